@@ -1,6 +1,8 @@
 package app
 
 import (
+	"context"
+
 	"github.com/ampliway/way-lib-go/cache"
 	"github.com/ampliway/way-lib-go/msg"
 	"github.com/ampliway/way-lib-go/storage"
@@ -14,4 +16,5 @@ type V1[T any] interface {
 	Storage() storage.V1
 	Cache() cache.V1
 	ID() string
+	Shutdown(ctx context.Context) error
 }
@@ -1,8 +1,11 @@
 package v1
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/ampliway/way-lib-go/app"
 	"github.com/ampliway/way-lib-go/cache"
@@ -17,79 +20,287 @@ import (
 )
 
 var (
-	_                app.V1[any] = (*App[any])(nil)
-	errSubModuleInit             = errors.New("sub-module failed on init")
+	_                    app.V1[any]    = (*App[any])(nil)
+	_                    msg.ProducerV1 = failedModule{}
+	_                    storage.V1     = failedModule{}
+	_                    cache.V1       = failedModule{}
+	_                    cache.Locker   = failedModule{}
+	errSubModuleInit                    = errors.New("sub-module failed on init")
+	errUnknownModule                    = errors.New("unknown module")
+	errSubModuleShutdown                = errors.New("sub-module failed on shutdown")
 )
 
+// App connects each module on first use. The modules given to New are
+// connected up front so their errors surface before any work starts.
 type App[T any] struct {
-	config  config.V1[T]
-	msg     msg.ProducerV1
-	storage storage.V1
-	cache   cache.V1
+	config  lazy[config.V1[T]]
+	msg     lazy[msg.ProducerV1]
+	storage lazy[storage.V1]
+	cache   lazy[cache.V1]
 	id      id.ID
 }
 
-func New[T any]() (*App[T], error) {
-	cfg, err := configV1.New[T]()
-	if err != nil {
-		return nil, fmt.Errorf("%w: %w: %s", errSubModuleInit, err, config.MODULE_NAME)
+type lazy[M any] struct {
+	mux   sync.Mutex
+	init  func() (M, error)
+	done  bool
+	value M
+	err   error
+}
+
+func (l *lazy[M]) get() (M, error) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	if !l.done {
+		l.value, l.err = l.init()
+		l.done = true
 	}
 
-	natsConfig, err := configV1.New[msgV1.Config]()
-	if err != nil {
-		return nil, fmt.Errorf("%w: %w: %s", errSubModuleInit, err, msg.MODULE_NAME)
+	return l.value, l.err
+}
+
+// connected returns the module only if it was already connected successfully.
+func (l *lazy[M]) connected() (any, bool) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	return l.value, l.done && l.err == nil
+}
+
+// Modules lists every module an App can connect, in dependency order.
+func Modules() []string {
+	return []string{config.MODULE_NAME, msg.MODULE_NAME, storage.MODULE_NAME, cache.MODULE_NAME}
+}
+
+func New[T any](modules ...string) (*App[T], error) {
+	a := &App[T]{
+		id: id.New(),
 	}
 
-	m, err := msgV1.New(natsConfig.Get(), id.New())
-	if err != nil {
-		return nil, fmt.Errorf("%w: %w: %s", errSubModuleInit, err, msg.MODULE_NAME)
+	a.config.init = func() (config.V1[T], error) {
+		return configV1.New[T]()
 	}
 
-	storageConfig, err := configV1.New[storageV1.Config]()
-	if err != nil {
-		return nil, fmt.Errorf("%w: %w: %s", errSubModuleInit, err, storage.MODULE_NAME)
+	a.msg.init = func() (msg.ProducerV1, error) {
+		msgConfig, err := configV1.New[msgV1.Config]()
+		if err != nil {
+			return nil, err
+		}
+
+		return msgV1.New(msgConfig.Get(), id.New())
 	}
 
-	s, err := storageV1.New(storageConfig.Get(), id.New())
-	if err != nil {
-		return nil, fmt.Errorf("%w: %w: %s", errSubModuleInit, err, storage.MODULE_NAME)
+	a.storage.init = func() (storage.V1, error) {
+		storageConfig, err := configV1.New[storageV1.Config]()
+		if err != nil {
+			return nil, err
+		}
+
+		return storageV1.New(storageConfig.Get(), id.New())
 	}
 
-	cacheConfig, err := configV1.New[cacheV1.Config]()
-	if err != nil {
-		return nil, fmt.Errorf("%w: %w: %s", errSubModuleInit, err, cache.MODULE_NAME)
+	a.cache.init = func() (cache.V1, error) {
+		cacheConfig, err := configV1.New[cacheV1.Config]()
+		if err != nil {
+			return nil, err
+		}
+
+		return cacheV1.New(cacheConfig.Get())
+	}
+
+	if err := a.Connect(modules...); err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// Connect connects the named modules now, when they are not yet.
+func (a *App[T]) Connect(modules ...string) error {
+	for _, module := range modules {
+		if err := a.connect(module); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *App[T]) connect(module string) error {
+	var err error
+
+	switch module {
+	case config.MODULE_NAME:
+		_, err = a.config.get()
+	case msg.MODULE_NAME:
+		_, err = a.msg.get()
+	case storage.MODULE_NAME:
+		_, err = a.storage.get()
+	case cache.MODULE_NAME:
+		_, err = a.cache.get()
+	default:
+		return fmt.Errorf("%s: %w: %s", app.MODULE_NAME, errUnknownModule, module)
 	}
 
-	c, err := cacheV1.New(cacheConfig.Get())
 	if err != nil {
-		return nil, fmt.Errorf("%w: %w: %s", errSubModuleInit, err, cache.MODULE_NAME)
+		return fmt.Errorf("%w: %w: %s", errSubModuleInit, err, module)
 	}
 
-	return &App[T]{
-		config:  cfg,
-		msg:     m,
-		storage: s,
-		cache:   c,
-		id:      id.New(),
-	}, nil
+	return nil
 }
 
+// Config returns nil when the config fails to load; commands relying on it
+// should connect the config module up front.
 func (a *App[T]) Config() *T {
-	return a.config.Get()
+	cfg, err := a.config.get()
+	if err != nil {
+		return nil
+	}
+
+	return cfg.Get()
 }
 
 func (a *App[T]) Msg() msg.ProducerV1 {
-	return a.msg
+	m, err := a.msg.get()
+	if err != nil {
+		return failedModule{err: fmt.Errorf("%w: %w: %s", errSubModuleInit, err, msg.MODULE_NAME)}
+	}
+
+	return m
 }
 
 func (a *App[T]) Storage() storage.V1 {
-	return a.storage
+	s, err := a.storage.get()
+	if err != nil {
+		return failedModule{err: fmt.Errorf("%w: %w: %s", errSubModuleInit, err, storage.MODULE_NAME)}
+	}
+
+	return s
 }
 
 func (a *App[T]) Cache() cache.V1 {
-	return a.cache
+	c, err := a.cache.get()
+	if err != nil {
+		return failedModule{err: fmt.Errorf("%w: %w: %s", errSubModuleInit, err, cache.MODULE_NAME)}
+	}
+
+	return c
 }
 
 func (a *App[T]) ID() string {
 	return a.id.Random()
 }
+
+// Shutdown closes the connected modules in reverse dependency order. A module
+// still closing when ctx is done is reported and left behind.
+func (a *App[T]) Shutdown(ctx context.Context) error {
+	modules := Modules()
+	errs := []error{}
+
+	for i := len(modules) - 1; i >= 0; i-- {
+		module, connected := a.module(modules[i]).connected()
+		if !connected {
+			continue
+		}
+
+		if err := closeModule(ctx, module); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %w: %s", errSubModuleShutdown, err, modules[i]))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (a *App[T]) module(name string) interface{ connected() (any, bool) } {
+	switch name {
+	case config.MODULE_NAME:
+		return &a.config
+	case msg.MODULE_NAME:
+		return &a.msg
+	case storage.MODULE_NAME:
+		return &a.storage
+	default:
+		return &a.cache
+	}
+}
+
+func closeModule(ctx context.Context, module any) error {
+	var closeFunc func() error
+
+	switch m := module.(type) {
+	case interface{ Close() error }:
+		closeFunc = m.Close
+	case interface{ Close() }:
+		closeFunc = func() error {
+			m.Close()
+
+			return nil
+		}
+	case interface{ Shutdown() }:
+		closeFunc = func() error {
+			m.Shutdown()
+
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- closeFunc()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// failedModule stands in for a module that failed to connect on first use,
+// returning the init error from every call.
+type failedModule struct {
+	err error
+}
+
+func (f failedModule) Publish(key string, m interface{}) error {
+	return f.err
+}
+
+func (f failedModule) PublishT(topicName, key string, m interface{}) error {
+	return f.err
+}
+
+func (f failedModule) CreateTopicIfNotExist(topicName string, numPartitions int32, replicationFactor int16) error {
+	return f.err
+}
+
+func (f failedModule) Shutdown() {}
+
+func (f failedModule) Save(config *storage.SaveConfig) (string, error) {
+	return "", f.err
+}
+
+func (f failedModule) Delete(objectName string) error {
+	return f.err
+}
+
+func (f failedModule) Link(objectName string, expiration time.Duration) (string, error) {
+	return "", f.err
+}
+
+func (f failedModule) Set(key string, data string, expiration time.Duration) error {
+	return f.err
+}
+
+func (f failedModule) Get(key string) (string, error) {
+	return "", f.err
+}
+
+func (f failedModule) SetNX(key string, data string, expiration time.Duration) (bool, error) {
+	return false, f.err
+}
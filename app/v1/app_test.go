@@ -0,0 +1,141 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ampliway/way-lib-go/cache"
+	"github.com/ampliway/way-lib-go/config"
+	"github.com/ampliway/way-lib-go/msg"
+	"github.com/ampliway/way-lib-go/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+type testConfig struct {
+	Field1 string `required:"false" default:"a"`
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	a, err := New[testConfig]()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &testConfig{Field1: "a"}, a.Config())
+	assert.NotEmpty(t, a.ID())
+
+	a, err = New[testConfig](config.MODULE_NAME)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &testConfig{Field1: "a"}, a.Config())
+
+	_, err = New[testConfig]("queue")
+	assert.Equal(t, "app: unknown module: queue", err.Error())
+}
+
+func TestNew_LazyFailure(t *testing.T) {
+	t.Parallel()
+
+	a, err := New[testConfig]()
+	assert.Equal(t, nil, err)
+
+	err = a.Msg().Publish("key", "value")
+	assert.ErrorIs(t, err, errSubModuleInit)
+	assert.Contains(t, err.Error(), "KAFKA_SERVERS")
+	assert.Equal(t, err, a.Msg().Publish("key", "value"))
+
+	_, err = New[testConfig](msg.MODULE_NAME)
+	assert.ErrorIs(t, err, errSubModuleInit)
+}
+
+func TestNew_MsgMisconfigured(t *testing.T) {
+	for name, value := range map[string]string{
+		"KAFKA_SERVERS":   "localhost:9092",
+		"KAFKA_USERNAME":  "user",
+		"KAFKA_PASSWORD":  "password",
+		"KAFKA_ALGORITHM": "md5",
+		"KAFKA_CA_FILE":   "ca.pem",
+		"KAFKA_CERT_FILE": "cert.pem",
+		"KAFKA_KEY_FILE":  "key.pem",
+	} {
+		t.Setenv(name, value)
+	}
+
+	a, err := New[testConfig]()
+	assert.Equal(t, nil, err)
+
+	err = a.Msg().Publish("key", "value")
+	assert.ErrorIs(t, err, errSubModuleInit)
+	assert.Contains(t, err.Error(), `invalid SASL algorithm, can be either "sha256" or "sha512": "md5"`)
+}
+
+type closerModule struct {
+	name   string
+	closed *[]string
+	err    error
+	block  bool
+}
+
+func (c *closerModule) Close() error {
+	if c.block {
+		select {}
+	}
+
+	*c.closed = append(*c.closed, c.name)
+
+	return c.err
+}
+
+func (c *closerModule) Set(key string, data string, expiration time.Duration) error { return nil }
+func (c *closerModule) Get(key string) (string, error)                              { return "", nil }
+
+func (c *closerModule) Save(config *storage.SaveConfig) (string, error) { return "", nil }
+func (c *closerModule) Delete(objectName string) error                  { return nil }
+func (c *closerModule) Link(objectName string, expiration time.Duration) (string, error) {
+	return "", nil
+}
+
+func TestShutdown(t *testing.T) {
+	t.Parallel()
+
+	closed := []string{}
+
+	a, err := New[testConfig]()
+	assert.Equal(t, nil, err)
+
+	a.storage.init = func() (storage.V1, error) {
+		return &closerModule{name: storage.MODULE_NAME, closed: &closed}, nil
+	}
+	a.cache.init = func() (cache.V1, error) {
+		return &closerModule{name: cache.MODULE_NAME, closed: &closed, err: errors.New("already closed")}, nil
+	}
+
+	assert.Equal(t, nil, a.Shutdown(context.Background()))
+	assert.Empty(t, closed)
+
+	a.Storage()
+	a.Cache()
+	a.Msg()
+
+	err = a.Shutdown(context.Background())
+	assert.Equal(t, "sub-module failed on shutdown: already closed: cache", err.Error())
+	assert.Equal(t, []string{cache.MODULE_NAME, storage.MODULE_NAME}, closed)
+}
+
+func TestShutdown_Timeout(t *testing.T) {
+	t.Parallel()
+
+	a, err := New[testConfig]()
+	assert.Equal(t, nil, err)
+
+	a.cache.init = func() (cache.V1, error) {
+		return &closerModule{block: true}, nil
+	}
+	a.Cache()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err = a.Shutdown(ctx)
+	assert.Equal(t, "sub-module failed on shutdown: context deadline exceeded: cache", err.Error())
+}
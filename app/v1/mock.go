@@ -0,0 +1,157 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/ampliway/way-lib-go/app"
+	"github.com/ampliway/way-lib-go/cache"
+	cacheV1 "github.com/ampliway/way-lib-go/cache/v1"
+	"github.com/ampliway/way-lib-go/helper/id"
+	"github.com/ampliway/way-lib-go/msg"
+	msgV1 "github.com/ampliway/way-lib-go/msg/v1"
+	"github.com/ampliway/way-lib-go/storage"
+	storageV1 "github.com/ampliway/way-lib-go/storage/v1"
+)
+
+var _ app.V1[any] = (*Mock[any])(nil)
+
+// Mock is an app backed by in-memory modules, for tests. Any module can be
+// replaced before use.
+type Mock[T any] struct {
+	mux       sync.Mutex
+	config    *T
+	msg       msg.ProducerV1
+	storage   storage.V1
+	cache     cache.V1
+	id        id.ID
+	connected []string
+	shutdowns int
+}
+
+func NewMock[T any](config *T) *Mock[T] {
+	if config == nil {
+		config = new(T)
+	}
+
+	return &Mock[T]{
+		mux:       sync.Mutex{},
+		config:    config,
+		msg:       msgV1.NewMock(),
+		storage:   storageV1.NewMock(),
+		cache:     cacheV1.NewMock(),
+		id:        id.New(),
+		connected: []string{},
+	}
+}
+
+func (m *Mock[T]) SetMsg(producer msg.ProducerV1) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.msg = producer
+}
+
+func (m *Mock[T]) SetStorage(storage storage.V1) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.storage = storage
+}
+
+func (m *Mock[T]) SetCache(cache cache.V1) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.cache = cache
+}
+
+func (m *Mock[T]) SetID(id id.ID) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.id = id
+}
+
+// Connect records the modules a command asked for; they are always ready.
+func (m *Mock[T]) Connect(modules ...string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	for _, module := range modules {
+		if !isModule(module) {
+			return fmt.Errorf("%s: %w: %s", app.MODULE_NAME, errUnknownModule, module)
+		}
+
+		m.connected = append(m.connected, module)
+	}
+
+	return nil
+}
+
+func (m *Mock[T]) Config() *T {
+	return m.config
+}
+
+func (m *Mock[T]) Msg() msg.ProducerV1 {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	return m.msg
+}
+
+func (m *Mock[T]) Storage() storage.V1 {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	return m.storage
+}
+
+func (m *Mock[T]) Cache() cache.V1 {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	return m.cache
+}
+
+func (m *Mock[T]) ID() string {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	return m.id.Random()
+}
+
+func (m *Mock[T]) Shutdown(ctx context.Context) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.shutdowns++
+
+	return nil
+}
+
+// Connected lists the modules passed to Connect, in order.
+func (m *Mock[T]) Connected() []string {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	return append([]string{}, m.connected...)
+}
+
+func (m *Mock[T]) Shutdowns() int {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	return m.shutdowns
+}
+
+func isModule(name string) bool {
+	for _, module := range Modules() {
+		if module == name {
+			return true
+		}
+	}
+
+	return false
+}
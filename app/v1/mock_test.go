@@ -0,0 +1,39 @@
+package v1
+
+import (
+	"context"
+	"testing"
+
+	cacheV1 "github.com/ampliway/way-lib-go/cache/v1"
+	"github.com/ampliway/way-lib-go/helper/id"
+	"github.com/ampliway/way-lib-go/msg"
+	msgV1 "github.com/ampliway/way-lib-go/msg/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMock(t *testing.T) {
+	t.Parallel()
+
+	mock := NewMock(&testConfig{Field1: "b"})
+	assert.Equal(t, &testConfig{Field1: "b"}, mock.Config())
+	assert.Equal(t, &testConfig{}, NewMock[testConfig](nil).Config())
+
+	assert.Equal(t, nil, mock.Connect(msg.MODULE_NAME))
+	assert.Equal(t, "app: unknown module: queue", mock.Connect("queue").Error())
+	assert.Equal(t, []string{msg.MODULE_NAME}, mock.Connected())
+
+	assert.Equal(t, nil, mock.Msg().PublishT("orders", "k", "created"))
+	assert.Len(t, mock.Msg().(*msgV1.Mock).Messages(), 1)
+
+	idMock := id.NewMock()
+	idMock.ExpectRandom("fixed")
+	mock.SetID(idMock)
+	assert.Equal(t, "fixed", mock.ID())
+
+	cache := cacheV1.NewMock()
+	mock.SetCache(cache)
+	assert.Same(t, cache, mock.Cache())
+
+	assert.Equal(t, nil, mock.Shutdown(context.Background()))
+	assert.Equal(t, 1, mock.Shutdowns())
+}
@@ -8,3 +8,9 @@ type V1 interface {
 	Set(key string, data string, expiration time.Duration) error
 	Get(key string) (string, error)
 }
+
+// Locker is implemented by the caches that can set a key only when it is not
+// set yet, reporting whether they did, to elect one process across replicas.
+type Locker interface {
+	SetNX(key string, data string, expiration time.Duration) (bool, error)
+}
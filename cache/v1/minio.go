@@ -10,7 +10,10 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
-var _ cache.V1 = (*Redis)(nil)
+var (
+	_ cache.V1     = (*Redis)(nil)
+	_ cache.Locker = (*Redis)(nil)
+)
 
 type Redis struct {
 	client *redis.Client
@@ -51,3 +54,11 @@ func (r *Redis) Get(key string) (string, error) {
 
 	return value, nil
 }
+
+func (r *Redis) SetNX(key string, data string, expiration time.Duration) (bool, error) {
+	return r.client.SetNX(context.Background(), fmt.Sprintf("%s-%s", r.prefix, key), data, expiration).Result()
+}
+
+func (r *Redis) Close() error {
+	return r.client.Close()
+}
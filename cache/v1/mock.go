@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ampliway/way-lib-go/cache"
+)
+
+var (
+	_ cache.V1     = (*Mock)(nil)
+	_ cache.Locker = (*Mock)(nil)
+)
+
+type Mock struct {
+	mux   sync.Mutex
+	items map[string]mockItem
+	now   func() time.Time
+}
+
+type mockItem struct {
+	data      string
+	expiresAt time.Time
+}
+
+func NewMock() *Mock {
+	return &Mock{
+		mux:   sync.Mutex{},
+		items: map[string]mockItem{},
+		now:   time.Now,
+	}
+}
+
+func (m *Mock) Set(key string, data string, expiration time.Duration) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	item := mockItem{data: data}
+	if expiration > 0 {
+		item.expiresAt = m.now().Add(expiration)
+	}
+
+	m.items[key] = item
+
+	return nil
+}
+
+func (m *Mock) Get(key string) (string, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	item, exist := m.get(key)
+	if !exist {
+		return "", nil
+	}
+
+	return item.data, nil
+}
+
+func (m *Mock) SetNX(key string, data string, expiration time.Duration) (bool, error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if _, exist := m.get(key); exist {
+		return false, nil
+	}
+
+	item := mockItem{data: data}
+	if expiration > 0 {
+		item.expiresAt = m.now().Add(expiration)
+	}
+
+	m.items[key] = item
+
+	return true, nil
+}
+
+func (m *Mock) get(key string) (mockItem, bool) {
+	item, exist := m.items[key]
+	if !exist {
+		return mockItem{}, false
+	}
+
+	if !item.expiresAt.IsZero() && !m.now().Before(item.expiresAt) {
+		delete(m.items, key)
+
+		return mockItem{}, false
+	}
+
+	return item, true
+}
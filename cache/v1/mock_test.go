@@ -0,0 +1,50 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMock(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+
+	mock := NewMock()
+	mock.now = func() time.Time { return now }
+
+	value, err := mock.Get("missing")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "", value)
+
+	assert.Equal(t, nil, mock.Set("forever", "a", 0))
+	assert.Equal(t, nil, mock.Set("short", "b", time.Minute))
+
+	value, _ = mock.Get("short")
+	assert.Equal(t, "b", value)
+
+	now = now.Add(time.Minute)
+
+	value, _ = mock.Get("short")
+	assert.Equal(t, "", value)
+
+	value, _ = mock.Get("forever")
+	assert.Equal(t, "a", value)
+
+	set, err := mock.SetNX("lock", "owner-1", time.Minute)
+	assert.Equal(t, nil, err)
+	assert.True(t, set)
+
+	set, _ = mock.SetNX("lock", "owner-2", time.Minute)
+	assert.False(t, set)
+
+	value, _ = mock.Get("lock")
+	assert.Equal(t, "owner-1", value)
+
+	now = now.Add(time.Minute)
+
+	set, _ = mock.SetNX("lock", "owner-2", time.Minute)
+	assert.True(t, set)
+}
@@ -1,16 +1,180 @@
 package cmd
 
-import "github.com/ampliway/way-lib-go/app"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
 
-const MODULE_NAME = "cmd"
+	"github.com/ampliway/way-lib-go/app"
+)
+
+const (
+	MODULE_NAME = "cmd"
+
+	ExitOK      = 0
+	ExitFailure = 1
+	ExitUsage   = 2
+
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
 
 type V1[T any] interface {
 	Add(config *Config[T]) error
-	Run(arguments ...string)
+	Use(middleware ...Middleware[T])
+	Run(arguments ...string) error
+	Main()
 }
 
+// Handler runs a command; Middleware wraps it with behaviour shared by every
+// command.
+type (
+	Handler[T any]    func(app app.V1[T], in *Input) error
+	Middleware[T any] func(next Handler[T]) Handler[T]
+)
+
+// Config describes a command. A config with Commands is a group: its children
+// are matched against the next argument, at any depth. A group may leave
+// Execute nil, in which case one of its children must be named.
+//
+// Flags is a pointer to a struct declaring the "--flag" options of the
+// command with the config struct tags: field LimitRows is "--limit-rows" and,
+// as with config, fields are required unless tagged with a default or
+// required:"false". A new value of that type is parsed for every run.
+//
+// Usage describes the positional args in the help, like "<topic> [partition]",
+// and Examples are full command lines shown below it.
+//
+// Modules names the app modules (config.MODULE_NAME, msg.MODULE_NAME, ...)
+// connected before Execute; the others connect on first use. A nil Modules
+// connects all of them, an empty one none, for commands that work offline.
+//
+// A long-running command sets Workers instead of Execute: the workers it
+// returns are supervised until the process is asked to stop.
 type Config[T any] struct {
 	Name        string
 	Description string
-	Execute     func(app app.V1[T]) error
+	Aliases     []string
+	Usage       string
+	Examples    []string
+	Commands    []*Config[T]
+	Flags       any
+	Modules     []string
+	Execute     func(app app.V1[T], in *Input) error
+	Workers     func(app app.V1[T], in *Input) ([]*WorkerConfig, error)
+}
+
+// Worker is a long-running part of a service. Start runs it until ctx is done
+// or it fails; Stop asks it to release its resources and may be called even if
+// Start already returned.
+type Worker interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// WorkerConfig names a worker for the supervisor. A worker whose Start fails
+// is restarted with backoff, unless it is Critical: then every worker stops
+// and the command fails.
+type WorkerConfig struct {
+	Name     string
+	Worker   Worker
+	Critical bool
+}
+
+// Output renders the results a command writes in the format chosen with the
+// global --output flag: a table by default, JSON or YAML for scripts. A slice
+// of structs is a table row per item, its columns named after the json tags.
+type Output interface {
+	Write(value any) error
+}
+
+// JobRun is one run of a scheduled command: its command line, the cron
+// expression and time it was due, when it ran and how it ended.
+type JobRun struct {
+	Command     string
+	Schedule    string
+	ScheduledAt time.Time
+	StartedAt   time.Time
+	EndedAt     time.Time
+	Err         error
+}
+
+// Input carries the canonical Path of the command and what was typed after
+// it: the positional Args and the parsed Flags, of the same type as
+// Config.Flags. ConfigArgs are the "-KEY=value" and "-f=file" arguments of the
+// run, for commands loading config themselves, and Modules the app modules
+// connected for the command, nil meaning all of them. Commands read Stdin and
+// print to Stdout and Stderr rather than use the os files, so their input and
+// output can be substituted; results meant for other programs go to Output
+// instead.
+type Input struct {
+	Path       []string
+	Args       []string
+	Flags      any
+	ConfigArgs []string
+	Modules    []string
+	Stdin      io.Reader
+	Stdout     io.Writer
+	Stderr     io.Writer
+	Output     Output
+
+	ctx context.Context
+}
+
+// Context is cancelled when the process receives SIGINT or SIGTERM; commands
+// should return promptly once it is done.
+func (in *Input) Context() context.Context {
+	if in.ctx == nil {
+		return context.Background()
+	}
+
+	return in.ctx
+}
+
+// WithContext returns a shallow copy of in using ctx.
+func (in *Input) WithContext(ctx context.Context) *Input {
+	result := *in
+	result.ctx = ctx
+
+	return &result
+}
+
+// ExitError is returned by a command to choose the exit code of the process.
+// A nil Err exits with Code without printing anything.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func Exit(code int, err error) error {
+	return &ExitError{Code: code, Err: err}
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode maps the error of a run to the exit code of the process.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+
+	return ExitFailure
 }
@@ -0,0 +1,312 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/ampliway/way-lib-go/cmd"
+	configV1 "github.com/ampliway/way-lib-go/config/v1"
+)
+
+const (
+	flagPrefix     = "--"
+	flagTerminator = "--"
+	flagSeparator  = "-"
+	flagTrue       = "true"
+	flagListJoin   = ","
+
+	flagRuleRequired = "required"
+
+	outputFlag = "--output"
+)
+
+// argv splits a single argument as a command line and keeps several as they
+// are, so that an argument holding blanks or quotes is never split again.
+func argv(arguments []string) ([]string, error) {
+	if len(arguments) == 1 {
+		return shellSplit(arguments[0])
+	}
+
+	return append([]string{}, arguments...), nil
+}
+
+// shellSplit splits a command line the way a POSIX shell does: blanks separate
+// words, single quotes keep everything literal, double quotes keep blanks and
+// only unescape \\, \", \$ and \` and a backslash outside quotes escapes the
+// next character.
+func shellSplit(input string) ([]string, error) {
+	result := []string{}
+	word := strings.Builder{}
+	inWord := false
+
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); i++ {
+		char := runes[i]
+
+		switch {
+		case unicode.IsSpace(char):
+			if inWord {
+				result = append(result, word.String())
+				word.Reset()
+				inWord = false
+			}
+		case char == '\\':
+			inWord = true
+
+			if i+1 < len(runes) {
+				i++
+				word.WriteRune(runes[i])
+			}
+		case char == '\'':
+			inWord = true
+
+			end := indexRune(runes, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("%s: %w", cmd.MODULE_NAME, errUnterminatedQuote)
+			}
+
+			word.WriteString(string(runes[i+1 : end]))
+			i = end
+		case char == '"':
+			inWord = true
+			closed := false
+
+			for i++; i < len(runes); i++ {
+				if runes[i] == '"' {
+					closed = true
+
+					break
+				}
+
+				if runes[i] == '\\' && i+1 < len(runes) && strings.ContainsRune("\\\"$`", runes[i+1]) {
+					i++
+				}
+
+				word.WriteRune(runes[i])
+			}
+
+			if !closed {
+				return nil, fmt.Errorf("%s: %w", cmd.MODULE_NAME, errUnterminatedQuote)
+			}
+		default:
+			inWord = true
+			word.WriteRune(char)
+		}
+	}
+
+	if inWord {
+		result = append(result, word.String())
+	}
+
+	return result, nil
+}
+
+func indexRune(runes []rune, from int, char rune) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == char {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// commandArgs splits the "-KEY=value" and "-f=file" arguments typed before
+// the command, which belong to the config loader, from the command line. Such
+// an argument after the command would be lost, so it is an error: a
+// positional arg of that shape goes after a "--" terminator.
+func commandArgs(args []string) ([]string, []string, error) {
+	i := 0
+	for i < len(args) && isConfigArg(args[i]) {
+		i++
+	}
+
+	for _, arg := range args[i:] {
+		if arg == flagTerminator {
+			break
+		}
+
+		if isConfigArg(arg) {
+			return nil, nil, fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errConfigArgPosition, arg)
+		}
+	}
+
+	return append([]string{}, args[i:]...), append([]string{}, args[:i]...), nil
+}
+
+func isConfigArg(arg string) bool {
+	return strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, flagPrefix) && strings.Contains(arg, "=")
+}
+
+// outputArgs takes the global --output flag out of args, wherever it is typed
+// before a "--" terminator.
+func outputArgs(args []string) (string, []string, error) {
+	format := cmd.OutputTable
+	result := []string{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == flagTerminator {
+			return format, append(result, args[i:]...), nil
+		}
+
+		if value, found := strings.CutPrefix(arg, outputFlag+"="); found {
+			format = value
+
+			continue
+		}
+
+		if arg == outputFlag {
+			if i+1 == len(args) {
+				return "", nil, fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errFlagValue, outputFlag)
+			}
+
+			i++
+			format = args[i]
+
+			continue
+		}
+
+		result = append(result, arg)
+	}
+
+	return format, result, nil
+}
+
+// flagName maps a config variable name to its flag: LIMIT_ROWS is --limit-rows.
+func flagName(name string) string {
+	return flagPrefix + strings.ToLower(strings.ReplaceAll(name, "_", flagSeparator))
+}
+
+func flagVariables(flags any) ([]configV1.Variable, error) {
+	if flags == nil {
+		return []configV1.Variable{}, nil
+	}
+
+	t := reflect.TypeOf(flags)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s: %w", cmd.MODULE_NAME, errConfigFlags)
+	}
+
+	vars, err := configV1.DescribeType(t.Elem())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %w", cmd.MODULE_NAME, errConfigFlags, err)
+	}
+
+	return vars, nil
+}
+
+// parseFlags splits args into the "--flag" values, keyed by variable name, and
+// the positional args. A flag takes "--name=value" or "--name value", bool
+// flags may be given alone and list or map flags may be repeated, their
+// values joined.
+func parseFlags(vars []configV1.Variable, args []string) (map[string]string, []string, error) {
+	values := map[string]string{}
+	positional := []string{}
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+
+		if arg == flagTerminator {
+			positional = append(positional, args[i+1:]...)
+
+			break
+		}
+
+		if !strings.HasPrefix(arg, flagPrefix) {
+			positional = append(positional, arg)
+
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(arg, "=")
+
+		variable := findVariable(vars, name)
+		if variable == nil {
+			return nil, nil, fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errUnknownFlag, name)
+		}
+
+		if !hasValue {
+			switch {
+			case isBoolFlag(*variable):
+				value = flagTrue
+			case i+1 < len(args):
+				i++
+				value = args[i]
+			default:
+				return nil, nil, fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errFlagValue, name)
+			}
+		}
+
+		if previous, exist := values[variable.Name]; exist {
+			if !isListFlag(*variable) {
+				return nil, nil, fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errFlagRepeated, name)
+			}
+
+			value = previous + flagListJoin + value
+		}
+
+		values[variable.Name] = value
+	}
+
+	return values, positional, nil
+}
+
+func isListFlag(v configV1.Variable) bool {
+	typ := strings.TrimPrefix(v.Type, "*")
+
+	return (strings.HasPrefix(typ, "[]") && typ != "[]uint8") || strings.HasPrefix(typ, "map[")
+}
+
+func findVariable(vars []configV1.Variable, flag string) *configV1.Variable {
+	for i := range vars {
+		if flagName(vars[i].Name) == flag {
+			return &vars[i]
+		}
+	}
+
+	return nil
+}
+
+// parseInput parses args into a new value of the type of flags and the
+// positional args, validated with the config rules. Flag values are taken
+// literally: no interpolation, secret reference nor decryption.
+func parseInput(flags any, args []string) (*cmd.Input, error) {
+	vars, err := flagVariables(flags)
+	if err != nil {
+		return nil, err
+	}
+
+	values, positional, err := parseFlags(vars, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if flags == nil {
+		return &cmd.Input{Args: positional}, nil
+	}
+
+	target := reflect.New(reflect.TypeOf(flags).Elem()).Interface()
+
+	if err := configV1.Decode(target, values); err != nil {
+		var report configV1.Errors
+		if errors.As(err, &report) {
+			for _, fieldErr := range report {
+				fieldErr.Name = flagName(fieldErr.Name)
+
+				if fieldErr.Rule == flagRuleRequired {
+					fieldErr.Err = errFlagMissing
+				}
+			}
+		}
+
+		return nil, fmt.Errorf("%s: %w: %w", cmd.MODULE_NAME, errInvalidFlags, err)
+	}
+
+	return &cmd.Input{Args: positional, Flags: target}, nil
+}
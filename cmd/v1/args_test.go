@@ -0,0 +1,176 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/ampliway/way-lib-go/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+type testFlags struct {
+	DryRun bool     `required:"false"`
+	Limit  int      `default:"10" min:"1"`
+	Topics []string `required:"false"`
+	Name   string   `env:"TARGET" required:"false"`
+}
+
+func TestShellSplit(t *testing.T) {
+	t.Parallel()
+
+	tableTest := []struct {
+		Input       string
+		Expected    []string
+		ExpectedErr string
+	}{
+		{Input: "", Expected: []string{}},
+		{Input: "  ", Expected: []string{}},
+		{Input: " a  b ", Expected: []string{"a", "b"}},
+		{Input: `a "b c" d`, Expected: []string{"a", "b c", "d"}},
+		{Input: `'a "b" $c'`, Expected: []string{`a "b" $c`}},
+		{Input: `"a \"b\" \n"`, Expected: []string{`a "b" \n`}},
+		{Input: `a\ b c`, Expected: []string{"a b", "c"}},
+		{Input: `--name="x y"z`, Expected: []string{"--name=x yz"}},
+		{Input: `a "" ''`, Expected: []string{"a", "", ""}},
+		{Input: `a "b`, ExpectedErr: "cmd: unterminated quote"},
+		{Input: `a 'b`, ExpectedErr: "cmd: unterminated quote"},
+	}
+
+	for _, rowTest := range tableTest {
+		actual, err := shellSplit(rowTest.Input)
+		if rowTest.ExpectedErr != "" {
+			assert.Equal(t, rowTest.ExpectedErr, err.Error())
+
+			continue
+		}
+
+		assert.Equal(t, nil, err)
+		assert.Equal(t, rowTest.Expected, actual)
+	}
+}
+
+func TestArgv(t *testing.T) {
+	t.Parallel()
+
+	actual, err := argv([]string{`echo --text "hello world"`})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"echo", "--text", "hello world"}, actual)
+
+	actual, err = argv([]string{"echo", "--text", "hello world", "it's"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"echo", "--text", "hello world", "it's"}, actual)
+
+	_, err = argv([]string{"echo it's"})
+	assert.Equal(t, "cmd: unterminated quote", err.Error())
+}
+
+func TestCommandArgs(t *testing.T) {
+	t.Parallel()
+
+	actual, loaderArgs, err := commandArgs([]string{"-f=config.yaml", "-FIELD_1=a", "db", "migrate", "-5", "--limit=2", "--", "-KEY=b"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []string{"db", "migrate", "-5", "--limit=2", "--", "-KEY=b"}, actual)
+	assert.Equal(t, []string{"-f=config.yaml", "-FIELD_1=a"}, loaderArgs)
+
+	_, _, err = commandArgs([]string{"db", "-FIELD_1=a", "migrate"})
+	assert.Equal(t, `cmd: config argument must come before the command, or after "--" as an arg: -FIELD_1=a`, err.Error())
+}
+
+func TestParseInput(t *testing.T) {
+	t.Parallel()
+
+	tableTest := []struct {
+		Scenario    string
+		Flags       any
+		Args        []string
+		Expected    *cmd.Input
+		ExpectedErr string
+	}{
+		{
+			Scenario: "no_flags",
+			Args:     []string{"a", "b"},
+			Expected: &cmd.Input{Args: []string{"a", "b"}},
+		},
+		{
+			Scenario:    "no_flags_declared",
+			Args:        []string{"--limit=1"},
+			ExpectedErr: "cmd: unknown flag: --limit",
+		},
+		{
+			Scenario: "defaults",
+			Flags:    &testFlags{},
+			Args:     []string{"a"},
+			Expected: &cmd.Input{Args: []string{"a"}, Flags: &testFlags{Limit: 10}},
+		},
+		{
+			Scenario: "all_forms",
+			Flags:    &testFlags{},
+			Args:     []string{"a", "--dry-run", "--limit", "3", "--topics=x", "--topics", "y", "--target=t", "b", "--", "--limit"},
+			Expected: &cmd.Input{
+				Args:  []string{"a", "b", "--limit"},
+				Flags: &testFlags{DryRun: true, Limit: 3, Topics: []string{"x", "y"}, Name: "t"},
+			},
+		},
+		{
+			Scenario: "bool_explicit",
+			Flags:    &testFlags{},
+			Args:     []string{"--dry-run=false"},
+			Expected: &cmd.Input{Args: []string{}, Flags: &testFlags{Limit: 10}},
+		},
+		{
+			Scenario: "literal_values",
+			Flags:    &testFlags{},
+			Args:     []string{"--target", "a ${x}", "--topics=enc:foo,secret://b"},
+			Expected: &cmd.Input{
+				Args:  []string{},
+				Flags: &testFlags{Limit: 10, Topics: []string{"enc:foo", "secret://b"}, Name: "a ${x}"},
+			},
+		},
+		{
+			Scenario:    "repeated_scalar",
+			Flags:       &testFlags{},
+			Args:        []string{"--limit=2", "--limit", "3"},
+			ExpectedErr: "cmd: flag given more than once: --limit",
+		},
+		{
+			Scenario:    "missing_value",
+			Flags:       &testFlags{},
+			Args:        []string{"--limit"},
+			ExpectedErr: "cmd: flag needs a value: --limit",
+		},
+		{
+			Scenario:    "unknown",
+			Flags:       &testFlags{},
+			Args:        []string{"--name=x"},
+			ExpectedErr: "cmd: unknown flag: --name",
+		},
+		{
+			Scenario:    "invalid",
+			Flags:       &testFlags{},
+			Args:        []string{"--limit=0"},
+			ExpectedErr: `cmd: invalid flags: config: --limit (int, min=1): value is below minimum: value "0"`,
+		},
+		{
+			Scenario:    "not_a_struct_pointer",
+			Flags:       testFlags{},
+			ExpectedErr: "cmd: config flags must be a pointer to a struct",
+		},
+	}
+
+	for _, rowTest := range tableTest {
+		rowTest := rowTest
+
+		t.Run(rowTest.Scenario, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := parseInput(rowTest.Flags, rowTest.Args)
+			if rowTest.ExpectedErr != "" {
+				assert.Equal(t, rowTest.ExpectedErr, err.Error())
+
+				return
+			}
+
+			assert.Equal(t, nil, err)
+			assert.Equal(t, rowTest.Expected, actual)
+		})
+	}
+}
@@ -1,17 +1,30 @@
 package v1
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 
 	"github.com/ampliway/way-lib-go/app"
 	appV1 "github.com/ampliway/way-lib-go/app/v1"
 	"github.com/ampliway/way-lib-go/cmd"
+	configV1 "github.com/ampliway/way-lib-go/config/v1"
 )
 
 const (
-	configMaxLen            = 10
+	formatJSONSchema = "json-schema"
+	formatEnv        = "env"
+	formatMarkdown   = "markdown"
+
+	cryptKeygen  = "keygen"
+	cryptEncrypt = "encrypt"
+	cryptRotate  = "rotate"
+
 	configNameMaxLen        = 20
 	configDescriptionMaxLen = 300
 )
@@ -19,12 +32,23 @@ const (
 var _ cmd.V1[any] = (*Cmd[any])(nil)
 
 type Cmd[T any] struct {
-	configs []*cmd.Config[T]
+	configs     []*cmd.Config[T]
+	middleware  []cmd.Middleware[T]
+	program     string
+	settings    settings
+	shellConfig *cmd.Config[T]
+	scheduler   atomic.Pointer[Scheduler[T]]
 }
 
-func New[T any]() *Cmd[T] {
+func New[T any](options ...Option) *Cmd[T] {
 	cmd := &Cmd[T]{
-		configs: []*cmd.Config[T]{},
+		configs:  []*cmd.Config[T]{},
+		program:  filepath.Base(os.Args[0]),
+		settings: defaultSettings(),
+	}
+
+	for _, option := range options {
+		option(&cmd.settings)
 	}
 
 	cmd.addReservedCommands()
@@ -33,18 +57,12 @@ func New[T any]() *Cmd[T] {
 }
 
 func (c *Cmd[T]) Add(config *cmd.Config[T]) error {
-	if len(c.configs)+1 > configMaxLen {
-		return fmt.Errorf("%s: %w", cmd.MODULE_NAME, errConfigLen)
-	}
-
 	if err := configIsValid(config); err != nil {
 		return err
 	}
 
-	for _, checkConfig := range c.configs {
-		if config.Name == checkConfig.Name {
-			return fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errConfigAlreadyExist, config.Name)
-		}
+	if err := checkConflict(c.configs, config); err != nil {
+		return err
 	}
 
 	c.configs = append(c.configs, config)
@@ -52,31 +70,178 @@ func (c *Cmd[T]) Add(config *cmd.Config[T]) error {
 	return nil
 }
 
-func (c *Cmd[T]) Run(arguments ...string) {
-	appModule, err := appV1.New[T]()
+// Session is what a run is bound to: the app handed to the command and the
+// files it uses. A nil App connects a new one for the run, shut down once the
+// command returns; an injected App is left open.
+type Session[T any] struct {
+	App    app.V1[T]
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+
+	// ctx, when set, replaces the signals as what cancels the command.
+	ctx context.Context
+}
+
+// Run executes the command named by arguments, or by os.Args when none are
+// given. A single argument is a command line, quoted as in a shell; several are
+// taken as argv unchanged. Usage mistakes are returned as a *cmd.ExitError with
+// cmd.ExitUsage.
+func (c *Cmd[T]) Run(arguments ...string) error {
+	args := os.Args[1:]
+
+	if len(arguments) > 1 || (len(arguments) == 1 && strings.TrimSpace(arguments[0]) != "") {
+		var err error
+
+		args, err = argv(arguments)
+		if err != nil {
+			return cmd.Exit(cmd.ExitUsage, err)
+		}
+	}
+
+	return c.run(&Session[T]{Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}, args)
+}
+
+// RunSession executes the command named by arguments, read as by Run, within
+// session: the "-KEY=value" and "-f=file" arguments given here, not os.Args,
+// are what the config and config-crypt commands load. A nil App is created by
+// appV1.New, which still loads its own config from the process; inject one to
+// control it. An injected app implementing Connect(modules ...string) error is
+// asked for the modules of the command.
+func (c *Cmd[T]) RunSession(session *Session[T], arguments ...string) error {
+	args, err := argv(arguments)
 	if err != nil {
-		panic(fmt.Errorf("%s: %w", cmd.MODULE_NAME, err))
+		return cmd.Exit(cmd.ExitUsage, err)
+	}
+
+	if session.Stdin == nil {
+		session.Stdin = strings.NewReader("")
+	}
+
+	if session.Stdout == nil {
+		session.Stdout = io.Discard
 	}
 
-	customArguments := strings.Join(arguments, " ")
-	if customArguments == "" {
-		customArguments = strings.Join(os.Args[1:], " ")
+	if session.Stderr == nil {
+		session.Stderr = io.Discard
+	}
+
+	return c.run(session, args)
+}
+
+func (c *Cmd[T]) run(session *Session[T], arguments []string) error {
+	format, args, err := outputArgs(arguments)
+	if err != nil {
+		return cmd.Exit(cmd.ExitUsage, err)
+	}
+
+	args, loaderArgs, err := commandArgs(args)
+	if err != nil {
+		return cmd.Exit(cmd.ExitUsage, err)
+	}
+
+	output, err := newOutput(format, session.Stdout)
+	if err != nil {
+		return cmd.Exit(cmd.ExitUsage, err)
 	}
 
-	args := replaceSpaceSplit(customArguments)
 	if len(args) == 0 || args[0] == "" {
-		panic(fmt.Errorf("%s: %w", cmd.MODULE_NAME, errEmptyArguments))
+		return cmd.Exit(cmd.ExitUsage, fmt.Errorf("%s: %w", cmd.MODULE_NAME, errEmptyArguments))
 	}
 
-	match := c.findConfig(args...)
+	match, path, rest := c.findConfig(args...)
+
+	if match == nil && wantsHelp(args[:1]) {
+		return writeHelp(session.Stdout, c.program, c.configs)
+	}
 
 	if match == nil {
-		panic(fmt.Errorf("%s: %w: %v", cmd.MODULE_NAME, errUnknown, args))
+		return cmd.Exit(cmd.ExitUsage, unknownCommand(c.configs, args[0]))
+	}
+
+	if wantsHelp(rest) {
+		return writeCommandHelp(session.Stdout, c.program, path, match)
+	}
+
+	if match.Execute == nil && match.Workers == nil {
+		if len(rest) > 0 {
+			return cmd.Exit(cmd.ExitUsage, unknownCommand(match.Commands, rest[0]))
+		}
+
+		return cmd.Exit(cmd.ExitUsage, fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errMissingSubcommand, strings.Join(path, " ")))
 	}
 
-	err = match.Execute(appModule)
+	in, err := parseInput(match.Flags, rest)
 	if err != nil {
-		panic(fmt.Errorf("%s: %w: %+v", cmd.MODULE_NAME, errExecutionFailed, match))
+		return cmd.Exit(cmd.ExitUsage, err)
+	}
+
+	modules := match.Modules
+	if modules == nil {
+		modules = appV1.Modules()
+	}
+
+	appModule, owned, err := sessionApp(session, modules)
+	if err != nil {
+		return fmt.Errorf("%s: %w", cmd.MODULE_NAME, err)
+	}
+
+	in.Path = path
+	in.ConfigArgs = loaderArgs
+	in.Modules = modules
+	in.Stdin = session.Stdin
+	in.Stdout = session.Stdout
+	in.Stderr = session.Stderr
+	in.Output = output
+
+	execute := cmd.Handler[T](match.Execute)
+	if match.Workers != nil {
+		execute = c.serve(match)
+	}
+
+	if session.ctx != nil {
+		return c.execute(session.ctx, nil, appModule, owned, path, c.chain(execute), in)
+	}
+
+	if match == c.shellConfig {
+		return c.execute(context.Background(), nil, appModule, owned, path, c.chain(execute), in)
+	}
+
+	return c.executeWithSignals(appModule, owned, path, c.chain(execute), in)
+}
+
+// sessionApp returns the app of the session, connecting the modules, or a new
+// one owned by the run.
+func sessionApp[T any](session *Session[T], modules []string) (app.V1[T], bool, error) {
+	if session.App == nil {
+		appModule, err := appV1.New[T](modules...)
+
+		return appModule, true, err
+	}
+
+	if connector, ok := session.App.(interface{ Connect(modules ...string) error }); ok {
+		if err := connector.Connect(modules...); err != nil {
+			return nil, false, err
+		}
+	}
+
+	return session.App, false, nil
+}
+
+// Main runs the command from os.Args, prints any error to stderr and exits
+// with its code.
+func (c *Cmd[T]) Main() {
+	err := c.Run()
+	printError(os.Stderr, err)
+
+	os.Exit(cmd.ExitCode(err))
+}
+
+// printError prints err unless it is an exit code without a message.
+func printError(w io.Writer, err error) {
+	var exitErr *cmd.ExitError
+	if err != nil && !(errors.As(err, &exitErr) && exitErr.Err == nil) {
+		fmt.Fprintln(w, err)
 	}
 }
 
@@ -103,57 +268,400 @@ func configIsValid[T any](config *cmd.Config[T]) error {
 		return fmt.Errorf("%s: %w", cmd.MODULE_NAME, errConfigDescriptionLen)
 	}
 
-	if config.Execute == nil {
+	for i, alias := range config.Aliases {
+		config.Aliases[i] = strings.TrimSpace(alias)
+		if config.Aliases[i] == "" {
+			return fmt.Errorf("%s: %w", cmd.MODULE_NAME, errConfigAliasEmpty)
+		}
+	}
+
+	vars, err := flagVariables(config.Flags)
+	if err != nil {
+		return err
+	}
+
+	for _, v := range vars {
+		if flagName(v.Name) == outputFlag {
+			return fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errConfigFlagReserved, outputFlag)
+		}
+	}
+
+	if config.Execute != nil && config.Workers != nil {
+		return fmt.Errorf("%s: %w", cmd.MODULE_NAME, errConfigExecuteWorkers)
+	}
+
+	if config.Execute == nil && config.Workers == nil && len(config.Commands) == 0 {
 		return fmt.Errorf("%s: %w", cmd.MODULE_NAME, errConfigExecuteNil)
 	}
 
+	for i, child := range config.Commands {
+		if err := configIsValid(child); err != nil {
+			return err
+		}
+
+		if err := checkConflict(config.Commands[:i], child); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-func replaceSpaceSplit(input string) []string {
-	input = strings.TrimSpace(input)
-	oldString := "  "
-	newString := " "
+// checkConflict reports whether the name or an alias of config is already
+// used by one of its siblings.
+func checkConflict[T any](siblings []*cmd.Config[T], config *cmd.Config[T]) error {
+	for _, name := range configNames(config) {
+		if matchConfig(siblings, name) != nil {
+			return fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errConfigAlreadyExist, name)
+		}
+	}
 
-	for strings.Contains(input, oldString) {
-		input = strings.ReplaceAll(input, oldString, newString)
+	return nil
+}
+
+func configNames[T any](config *cmd.Config[T]) []string {
+	return append([]string{config.Name}, config.Aliases...)
+}
+
+func matchConfig[T any](configs []*cmd.Config[T], name string) *cmd.Config[T] {
+	for _, config := range configs {
+		for _, configName := range configNames(config) {
+			if configName == name {
+				return config
+			}
+		}
 	}
 
-	if input == "" {
-		return []string{}
+	return nil
+}
+
+// findConfig walks the command tree as deep as the args match, returning the
+// deepest command, its canonical path and the remaining args.
+func (c *Cmd[T]) findConfig(args ...string) (*cmd.Config[T], []string, []string) {
+	var (
+		match *cmd.Config[T]
+		path  []string
+	)
+
+	configs := c.configs
+
+	for len(args) > 0 {
+		next := matchConfig(configs, args[0])
+		if next == nil {
+			break
+		}
+
+		match, path, configs, args = next, append(path, next.Name), next.Commands, args[1:]
 	}
 
-	return strings.Split(input, newString)
+	return match, path, args
 }
 
-func (c *Cmd[T]) findConfig(args ...string) *cmd.Config[T] {
+func (c *Cmd[T]) help(w io.Writer, args []string) error {
 	if len(args) == 0 {
-		return nil
+		return writeHelp(w, c.program, c.configs)
 	}
 
-	var match *cmd.Config[T]
+	match, path, rest := c.findConfig(args...)
+	if match == nil || len(rest) > 0 {
+		return fmt.Errorf("%s: %w: %v", cmd.MODULE_NAME, errUnknown, args)
+	}
 
-	for _, config := range c.configs {
-		if config.Name == args[0] {
-			match = config
+	return writeCommandHelp(w, c.program, path, match)
+}
 
-			break
-		}
+type commandRow struct {
+	Command     string `json:"command"`
+	Description string `json:"description"`
+}
+
+func commandRows[T any](configs []*cmd.Config[T]) []commandRow {
+	result := []commandRow{}
+	for _, entry := range commandEntries(configs, "") {
+		result = append(result, commandRow{Command: entry.path, Description: entry.config.Description})
+	}
+
+	return result
+}
+
+type commandEntry[T any] struct {
+	path   string
+	config *cmd.Config[T]
+}
+
+// commandEntries lists every command of the tree with its full path, each
+// group followed by its children.
+func commandEntries[T any](configs []*cmd.Config[T], prefix string) []commandEntry[T] {
+	result := []commandEntry[T]{}
+
+	for _, config := range configs {
+		path := strings.TrimSpace(prefix + " " + config.Name)
+		result = append(result, commandEntry[T]{path: path, config: config})
+		result = append(result, commandEntries(config.Commands, path)...)
 	}
 
-	return match
+	return result
 }
 
 func (c *Cmd[T]) addReservedCommands() {
 	c.configs = append(c.configs, &cmd.Config[T]{
 		Name:        "commands",
 		Description: "List all commands",
-		Execute: func(app app.V1[T]) error {
-			for _, command := range c.configs {
-				fmt.Println(command.Name)
+		Modules:     []string{},
+		Execute: func(app app.V1[T], in *cmd.Input) error {
+			return in.Output.Write(commandRows(c.configs))
+		},
+	}, &cmd.Config[T]{
+		Name:        "help",
+		Description: "Show the usage of the tool or of a command",
+		Usage:       "[command]...",
+		Examples:    []string{c.program + " help config-crypt"},
+		Modules:     []string{},
+		Execute: func(app app.V1[T], in *cmd.Input) error {
+			return c.help(in.Stdout, in.Args)
+		},
+	}, &cmd.Config[T]{
+		Name:        "completion",
+		Description: "Print the shell completion script for bash, zsh or fish",
+		Usage:       "bash|zsh|fish",
+		Examples:    []string{"source <(" + c.program + " completion bash)"},
+		Modules:     []string{},
+		Execute: func(app app.V1[T], in *cmd.Input) error {
+			if len(in.Args) != 1 {
+				return fmt.Errorf("%s: %w", cmd.MODULE_NAME, errUnknownShell)
 			}
 
-			return nil
+			nodes, err := completionNodes(c.configs)
+			if err != nil {
+				return err
+			}
+
+			return writeCompletion(in.Stdout, c.program, in.Args[0], nodes)
+		},
+	}, &cmd.Config[T]{
+		Name:        "config",
+		Description: "Show every resolved configuration value with its source, secrets masked",
+		Modules:     []string{},
+		Execute: func(app app.V1[T], in *cmd.Input) error {
+			env, err := configV1.Load[T](configV1.NewLoader(in.ConfigArgs, os.LookupEnv, nil))
+			if err != nil {
+				return err
+			}
+
+			return in.Output.Write(env.Fields())
+		},
+	}, &cmd.Config[T]{
+		Name:        "config-schema",
+		Description: "List the configuration variables, or render them as a Markdown table, a JSON Schema or a .env example",
+		Usage:       "[markdown|json-schema|env]",
+		Examples:    []string{c.program + " config-schema markdown > CONFIG.md"},
+		Modules:     []string{},
+		Execute: func(app app.V1[T], in *cmd.Input) error {
+			vars, err := configV1.Describe[T]()
+			if err != nil {
+				return err
+			}
+
+			if len(in.Args) == 0 {
+				return in.Output.Write(vars)
+			}
+
+			return writeSchema(in.Stdout, vars, in.Args[0])
+		},
+	}, &cmd.Config[T]{
+		Name:        "config-crypt",
+		Description: "Manage encrypted config values: \"keygen <key-file>\", \"encrypt\" reading the value from stdin or \"rotate <new-key-file> <file>...\", using the key pointed by CONFIG_KEY_FILE",
+		Examples:    []string{"printf %s \"$PASSWORD\" | " + c.program + " config-crypt encrypt"},
+		Modules:     []string{},
+		Execute: func(app app.V1[T], in *cmd.Input) error {
+			return runCrypt(in, configV1.NewLoader(in.ConfigArgs, os.LookupEnv, nil))
 		},
 	})
+
+	c.configs = append(c.configs, &cmd.Config[T]{
+		Name:        "schedule",
+		Description: "Run commands on cron expressions until stopped, logging every run to stderr",
+		Usage:       "<cron> <command> [<cron> <command>]...",
+		Examples: []string{
+			c.program + ` schedule "0 * * * *" cleanup`,
+			c.program + ` schedule --jitter 30s --lock "*/5 * * * *" "report --daily" @daily backup`,
+		},
+		Flags:   &scheduleFlags{},
+		Modules: []string{},
+		Workers: func(app app.V1[T], in *cmd.Input) ([]*cmd.WorkerConfig, error) {
+			if len(in.Args) == 0 || len(in.Args)%2 != 0 {
+				return nil, cmd.Exit(cmd.ExitUsage, fmt.Errorf("%s: %w", cmd.MODULE_NAME, errScheduleUsage))
+			}
+
+			flags := in.Flags.(*scheduleFlags)
+
+			options := []SchedulerOption{WithJobJitter(flags.Jitter), WithJobOutput(in.Stdout, in.Stderr), WithJobLog(in.Stderr)}
+			if flags.Lock {
+				options = append(options, WithJobLock())
+			}
+
+			scheduler := NewScheduler(c, app, options...)
+
+			for i := 0; i < len(in.Args); i += 2 {
+				if err := scheduler.Add(in.Args[i], in.Args[i+1]); err != nil {
+					return nil, cmd.Exit(cmd.ExitUsage, err)
+				}
+			}
+
+			c.scheduler.Store(scheduler)
+
+			return []*cmd.WorkerConfig{{Name: "scheduler", Worker: scheduler, Critical: true}}, nil
+		},
+	})
+
+	c.shellConfig = &cmd.Config[T]{
+		Name:        "shell",
+		Description: "Open an interactive prompt running commands over the same app connections, with history and tab completion",
+		Modules:     []string{},
+		Execute:     c.shell,
+	}
+	c.configs = append(c.configs, c.shellConfig)
+}
+
+// runCrypt runs a config-crypt subcommand. The value to encrypt is read from
+// stdin, never from the args, which would leak it to the shell history and
+// the process list.
+func runCrypt(in *cmd.Input, loader *configV1.Loader) error {
+	args := in.Args
+	if len(args) == 0 {
+		return fmt.Errorf("%s: %w", cmd.MODULE_NAME, errCryptUsage)
+	}
+
+	switch {
+	case args[0] == cryptKeygen && len(args) == 2:
+		key, err := configV1.GenerateKey()
+		if err != nil {
+			return err
+		}
+
+		file, err := os.OpenFile(args[1], os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		_, err = file.Write(key)
+
+		return err
+	case args[0] == cryptEncrypt && len(args) == 1:
+		key, err := loader.Key()
+		if err != nil {
+			return err
+		}
+
+		plain, err := io.ReadAll(in.Stdin)
+		if err != nil {
+			return err
+		}
+
+		value, err := configV1.Encrypt(key, strings.TrimRight(string(plain), "\r\n"))
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(in.Stdout, value)
+
+		return err
+	case args[0] == cryptRotate && len(args) >= 2:
+		oldKey, err := loader.Key()
+		if err != nil {
+			return err
+		}
+
+		newKeyData, err := os.ReadFile(args[1])
+		if err != nil {
+			return err
+		}
+
+		newKey, err := configV1.ParseKey(newKeyData)
+		if err != nil {
+			return err
+		}
+
+		for _, path := range args[2:] {
+			if err := rotateFile(oldKey, newKey, path); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(in.Stdout, path)
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("%s: %w", cmd.MODULE_NAME, errCryptUsage)
+	}
+}
+
+// rotateFile writes the rotated file next to path and renames it over, so an
+// interrupted rotation never leaves a half written file.
+func rotateFile(oldKey, newKey []byte, path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	rotated, err := configV1.Rotate(oldKey, newKey, data)
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, path)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(rotated); err != nil {
+		file.Close()
+
+		return err
+	}
+
+	if err := file.Chmod(info.Mode().Perm()); err != nil {
+		file.Close()
+
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+// writeSchema renders vars as a document in format, for files kept beside the
+// code rather than results read by scripts.
+func writeSchema(w io.Writer, vars []configV1.Variable, format string) error {
+	var data []byte
+
+	switch format {
+	case formatJSONSchema:
+		schema, err := configV1.JSONSchema(vars)
+		if err != nil {
+			return err
+		}
+
+		data = append(schema, '\n')
+	case formatEnv:
+		data = configV1.EnvExample(vars)
+	case formatMarkdown:
+		data = configV1.Markdown(vars)
+	default:
+		return fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errUnknownFormat, format)
+	}
+
+	_, err := w.Write(data)
+
+	return err
 }
@@ -1,13 +1,20 @@
 package v1
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
-	"strconv"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
 	"github.com/ampliway/way-lib-go/app"
+	appV1 "github.com/ampliway/way-lib-go/app/v1"
 	"github.com/ampliway/way-lib-go/cmd"
+	configV1 "github.com/ampliway/way-lib-go/config/v1"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -113,32 +120,6 @@ func TestAdd(t *testing.T) {
 	}
 }
 
-func TestAdd_ConfigMaxLen(t *testing.T) {
-	t.Parallel()
-
-	adapter := New[testConfig]()
-
-	for i := len(adapter.configs) + 1; i <= configMaxLen; i++ {
-		actualErr := adapter.Add(&cmd.Config[testConfig]{
-			Name:        "command_" + strconv.Itoa(i),
-			Description: "description " + strconv.Itoa(i),
-			Execute: func(app app.V1[testConfig]) error {
-				return nil
-			},
-		})
-		assert.Equal(t, nil, actualErr)
-	}
-
-	actualErr := adapter.Add(&cmd.Config[testConfig]{
-		Name:        "command_" + strconv.Itoa(configMaxLen+1),
-		Description: "description " + strconv.Itoa(configMaxLen+1),
-		Execute: func(app app.V1[testConfig]) error {
-			return nil
-		},
-	})
-	assert.Equal(t, "cmd: configs must be equal or less than 10", actualErr.Error())
-}
-
 func TestAdd_AlreadyExist(t *testing.T) {
 	t.Parallel()
 
@@ -147,7 +128,7 @@ func TestAdd_AlreadyExist(t *testing.T) {
 	configA := &cmd.Config[testConfig]{
 		Name:        "command A",
 		Description: "description",
-		Execute: func(app app.V1[testConfig]) error {
+		Execute: func(app app.V1[testConfig], in *cmd.Input) error {
 			return nil
 		},
 	}
@@ -155,7 +136,7 @@ func TestAdd_AlreadyExist(t *testing.T) {
 	configB := &cmd.Config[testConfig]{
 		Name:        configA.Name,
 		Description: "description",
-		Execute: func(app app.V1[testConfig]) error {
+		Execute: func(app app.V1[testConfig], in *cmd.Input) error {
 			return nil
 		},
 	}
@@ -167,24 +148,369 @@ func TestAdd_AlreadyExist(t *testing.T) {
 	assert.Equal(t, fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errConfigAlreadyExist, configA.Name), actualErr)
 }
 
-func TestReplaceSpaceSplit(t *testing.T) {
+func TestAdd_Nested(t *testing.T) {
+	t.Parallel()
+
+	execute := func(app app.V1[testConfig], in *cmd.Input) error {
+		return nil
+	}
+
+	tableTest := []struct {
+		Scenario    string
+		Config      *cmd.Config[testConfig]
+		ExpectedErr string
+	}{
+		{
+			Scenario: "group_without_execute",
+			Config: &cmd.Config[testConfig]{
+				Name:        "db",
+				Description: "Database commands",
+				Commands: []*cmd.Config[testConfig]{
+					{Name: "migrate", Description: "Run migrations", Execute: execute},
+				},
+			},
+		},
+		{
+			Scenario: "invalid_child",
+			Config: &cmd.Config[testConfig]{
+				Name:        "db",
+				Description: "Database commands",
+				Commands: []*cmd.Config[testConfig]{
+					{Name: "migrate", Description: "Run migrations"},
+				},
+			},
+			ExpectedErr: "cmd: config execute cannot be nil",
+		},
+		{
+			Scenario: "child_alias_conflict",
+			Config: &cmd.Config[testConfig]{
+				Name:        "db",
+				Description: "Database commands",
+				Commands: []*cmd.Config[testConfig]{
+					{Name: "migrate", Description: "Run migrations", Execute: execute},
+					{Name: "seed", Aliases: []string{"migrate"}, Description: "Seed data", Execute: execute},
+				},
+			},
+			ExpectedErr: "cmd: config already exist: migrate",
+		},
+		{
+			Scenario: "flags_not_a_struct_pointer",
+			Config: &cmd.Config[testConfig]{
+				Name:        "db",
+				Description: "Database commands",
+				Flags:       "limit",
+				Execute:     execute,
+			},
+			ExpectedErr: "cmd: config flags must be a pointer to a struct",
+		},
+		{
+			Scenario: "alias_empty",
+			Config: &cmd.Config[testConfig]{
+				Name:        "db",
+				Aliases:     []string{" "},
+				Description: "Database commands",
+				Execute:     execute,
+			},
+			ExpectedErr: "cmd: config alias cannot be empty",
+		},
+		{
+			Scenario: "alias_conflicts_with_reserved",
+			Config: &cmd.Config[testConfig]{
+				Name:        "db",
+				Aliases:     []string{"commands"},
+				Description: "Database commands",
+				Execute:     execute,
+			},
+			ExpectedErr: "cmd: config already exist: commands",
+		},
+	}
+
+	for _, rowTest := range tableTest {
+		rowTest := rowTest
+
+		t.Run(rowTest.Scenario, func(t *testing.T) {
+			t.Parallel()
+
+			actualErr := New[testConfig]().Add(rowTest.Config)
+			if rowTest.ExpectedErr == "" {
+				assert.Equal(t, nil, actualErr)
+
+				return
+			}
+
+			assert.Equal(t, rowTest.ExpectedErr, actualErr.Error())
+		})
+	}
+}
+
+func TestFindConfig(t *testing.T) {
 	t.Parallel()
 
-	actual := replaceSpaceSplit("")
-	assert.Equal(t, []string{}, actual)
+	execute := func(app app.V1[testConfig], in *cmd.Input) error {
+		return nil
+	}
+
+	adapter := New[testConfig]()
+	assert.Equal(t, nil, adapter.Add(&cmd.Config[testConfig]{
+		Name:        "topics",
+		Aliases:     []string{"t"},
+		Description: "Topic commands",
+		Execute:     execute,
+		Commands: []*cmd.Config[testConfig]{
+			{Name: "list", Aliases: []string{"ls"}, Description: "List topics", Execute: execute},
+			{
+				Name:        "partitions",
+				Description: "Partition commands",
+				Commands: []*cmd.Config[testConfig]{
+					{Name: "describe", Description: "Describe partitions", Execute: execute},
+				},
+			},
+		},
+	}))
+
+	tableTest := []struct {
+		Args         []string
+		ExpectedName string
+		ExpectedPath []string
+		ExpectedRest []string
+	}{
+		{Args: []string{"topics"}, ExpectedName: "topics", ExpectedPath: []string{"topics"}, ExpectedRest: []string{}},
+		{Args: []string{"t", "ls", "x"}, ExpectedName: "list", ExpectedPath: []string{"topics", "list"}, ExpectedRest: []string{"x"}},
+		{Args: []string{"topics", "other"}, ExpectedName: "topics", ExpectedPath: []string{"topics"}, ExpectedRest: []string{"other"}},
+		{
+			Args:         []string{"topics", "partitions", "describe", "a", "b"},
+			ExpectedName: "describe",
+			ExpectedPath: []string{"topics", "partitions", "describe"},
+			ExpectedRest: []string{"a", "b"},
+		},
+	}
+
+	for _, rowTest := range tableTest {
+		match, path, rest := adapter.findConfig(rowTest.Args...)
+		assert.Equal(t, rowTest.ExpectedName, match.Name)
+		assert.Equal(t, rowTest.ExpectedPath, path)
+		assert.Equal(t, rowTest.ExpectedRest, rest)
+	}
 
-	actual = replaceSpaceSplit(" ")
-	assert.Equal(t, []string{}, actual)
+	match, _, rest := adapter.findConfig("unknown")
+	assert.Nil(t, match)
+	assert.Equal(t, []string{"unknown"}, rest)
 
-	actual = replaceSpaceSplit("a ")
-	assert.Equal(t, []string{"a"}, actual)
+	paths := []string{}
+	for _, entry := range commandEntries(adapter.configs, "") {
+		paths = append(paths, entry.path)
+	}
+
+	assert.Equal(t, []string{"topics", "topics list", "topics partitions", "topics partitions describe"}, paths[len(paths)-4:])
+}
+
+func TestWriteSchema(t *testing.T) {
+	t.Parallel()
+
+	vars, err := configV1.Describe[testConfig]()
+	assert.Equal(t, nil, err)
+
+	output := &bytes.Buffer{}
+	assert.Equal(t, nil, writeSchema(output, vars, formatMarkdown))
+	assert.Equal(t, string(configV1.Markdown(vars)), output.String())
+
+	output.Reset()
+	assert.Equal(t, nil, writeSchema(output, vars, formatEnv))
+	assert.Equal(t, "# string, required\nFIELD_1=\n", output.String())
+
+	output.Reset()
+	assert.Equal(t, nil, writeSchema(output, vars, formatJSONSchema))
+	assert.Contains(t, output.String(), "\"FIELD_1\"")
+
+	err = writeSchema(output, vars, "xml")
+	assert.Equal(t, "cmd: unknown output format: xml", err.Error())
+}
+
+func TestRunCrypt(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	oldKeyFile := filepath.Join(dir, "old.key")
+	newKeyFile := filepath.Join(dir, "new.key")
+	configFile := filepath.Join(dir, "config.env")
+
+	output := &bytes.Buffer{}
+	input := func(stdin string, args ...string) *cmd.Input {
+		return &cmd.Input{Args: args, Stdin: strings.NewReader(stdin), Stdout: output}
+	}
+
+	err := runCrypt(input(""), configV1.NewLoader(nil, nil, nil))
+	assert.Equal(t, "cmd: usage: config-crypt keygen <key-file> | encrypt < value | rotate <new-key-file> <file>...", err.Error())
+
+	assert.Equal(t, nil, runCrypt(input("", cryptKeygen, oldKeyFile), configV1.NewLoader(nil, nil, nil)))
+	assert.Equal(t, nil, runCrypt(input("", cryptKeygen, newKeyFile), configV1.NewLoader(nil, nil, nil)))
+	assert.NotNil(t, runCrypt(input("", cryptKeygen, newKeyFile), configV1.NewLoader(nil, nil, nil)))
+
+	oldLoader := configV1.NewLoader([]string{"-" + configV1.KeyFileVar + "=" + oldKeyFile}, nil, nil)
+	err = runCrypt(input("", cryptEncrypt, "p@ss"), oldLoader)
+	assert.ErrorIs(t, err, errCryptUsage)
+	assert.Equal(t, nil, runCrypt(input("p@ss\n", cryptEncrypt), oldLoader))
+
+	sealed := strings.TrimSpace(output.String())
+	assert.True(t, strings.HasPrefix(sealed, configV1.EncryptedPrefix))
+	assert.Equal(t, nil, os.WriteFile(configFile, []byte("FIELD_1="+sealed+"\n"), 0o640))
+
+	output.Reset()
+	assert.Equal(t, nil, runCrypt(input("", cryptRotate, newKeyFile, configFile), oldLoader))
+	assert.Equal(t, configFile+"\n", output.String())
+
+	info, err := os.Stat(configFile)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+
+	entries, err := os.ReadDir(dir)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, len(entries))
+
+	newLoader := configV1.NewLoader([]string{"-f=" + configFile, "-" + configV1.KeyFileVar + "=" + newKeyFile}, nil, nil)
+	env, err := configV1.Load[testConfig](newLoader)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "p@ss", env.Get().Field1)
+}
+
+func TestRunSession_ConfigCrypt(t *testing.T) {
+	t.Parallel()
+
+	keyFile := filepath.Join(t.TempDir(), "config.key")
+	keyData, err := configV1.GenerateKey()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, os.WriteFile(keyFile, keyData, 0o600))
+
+	key, err := configV1.ParseKey(keyData)
+	assert.Equal(t, nil, err)
+
+	output := &bytes.Buffer{}
+	session := &Session[testConfig]{App: appV1.NewMock[testConfig](nil), Stdin: strings.NewReader("p@ss"), Stdout: output}
+
+	err = New[testConfig]().RunSession(session, "-"+configV1.KeyFileVar+"="+keyFile, "config-crypt", "encrypt")
+	assert.Equal(t, nil, err)
+
+	plain, err := configV1.Decrypt(key, strings.TrimSpace(output.String()))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "p@ss", plain)
+}
+
+func TestRunSession_Config(t *testing.T) {
+	t.Parallel()
+
+	for arguments, stdin := range map[string]string{
+		"-FIELD_1=session config --output json": "",
+		"-FIELD_1=session shell":                "config --output json\n",
+	} {
+		output := &bytes.Buffer{}
+		session := &Session[testConfig]{App: appV1.NewMock[testConfig](nil), Stdin: strings.NewReader(stdin), Stdout: output}
+
+		assert.Equal(t, nil, New[testConfig]().RunSession(session, arguments))
+
+		fields := []configV1.Field{}
+		assert.Equal(t, nil, json.Unmarshal(output.Bytes(), &fields))
+		assert.Equal(t, []configV1.Field{{Name: "FIELD_1", Type: "string", Source: configV1.SourceArgs, Value: "session"}}, fields)
+	}
+}
+
+func TestRunSession_ConfigTable(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	session := &Session[testConfig]{App: appV1.NewMock[testConfig](nil), Stdout: output}
+
+	assert.Equal(t, nil, New[testConfig]().RunSession(session, "-FIELD_1=session", "config"))
+	assert.Equal(t, ""+
+		"NAME     TYPE    SOURCE  ORIGIN  VALUE    SECRET\n"+
+		"FIELD_1  string  args            session  false\n", output.String())
+
+	output.Reset()
+	assert.Equal(t, nil, New[testConfig]().RunSession(session, "config-schema", "--output=json"))
+
+	vars := []configV1.Variable{}
+	assert.Equal(t, nil, json.Unmarshal(output.Bytes(), &vars))
+	assert.Len(t, vars, 1)
+	assert.Equal(t, "FIELD_1", vars[0].Name)
+	assert.True(t, vars[0].Required)
+}
+
+func TestRun_UsageErrors(t *testing.T) {
+	t.Parallel()
+
+	adapter := newHelpAdapter(t)
+
+	tableTest := []struct {
+		Arguments   string
+		ExpectedErr string
+	}{
+		{Arguments: "-FIELD_1=a", ExpectedErr: "cmd: with empty arguments"},
+		{Arguments: `topics "list`, ExpectedErr: "cmd: unterminated quote"},
+		{Arguments: "tpics list", ExpectedErr: `cmd: unknown command: tpics, did you mean "topics"?`},
+		{Arguments: "topics", ExpectedErr: "cmd: missing subcommand: topics"},
+		{Arguments: "t lst", ExpectedErr: `cmd: unknown command: lst, did you mean "list" or "ls"?`},
+		{Arguments: "topics list --limit=0", ExpectedErr: `cmd: invalid flags: config: 2 invalid variables: --limit (int, min=1): value is below minimum: value "0"; --filter (string, required): flag not set`},
+	}
+
+	for _, rowTest := range tableTest {
+		err := adapter.Run(rowTest.Arguments)
+		assert.Equal(t, rowTest.ExpectedErr, err.Error())
+		assert.Equal(t, cmd.ExitUsage, cmd.ExitCode(err))
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, cmd.ExitOK, cmd.ExitCode(nil))
+	assert.Equal(t, cmd.ExitFailure, cmd.ExitCode(errors.New("failed")))
+	assert.Equal(t, 3, cmd.ExitCode(fmt.Errorf("wrapped: %w", cmd.Exit(3, errors.New("not found")))))
+	assert.Equal(t, "not found", cmd.Exit(3, errors.New("not found")).Error())
+	assert.Equal(t, "exit status 4", cmd.Exit(4, nil).Error())
+}
+
+func TestRun_Execute(t *testing.T) {
+	t.Parallel()
+
+	var received *cmd.Input
+
+	adapter := New[testConfig]()
+	assert.Equal(t, nil, adapter.Add(&cmd.Config[testConfig]{
+		Name:        "db",
+		Description: "Database commands",
+		Commands: []*cmd.Config[testConfig]{
+			{
+				Name:        "migrate",
+				Description: "Run migrations",
+				Flags:       &testFlags{},
+				Modules:     []string{},
+				Execute: func(app app.V1[testConfig], in *cmd.Input) error {
+					received = in
+
+					return nil
+				},
+			},
+			{
+				Name:        "check",
+				Description: "Check the schema",
+				Modules:     []string{},
+				Execute: func(app app.V1[testConfig], in *cmd.Input) error {
+					return cmd.Exit(3, errors.New("schema drift"))
+				},
+			},
+		},
+	}))
 
-	actual = replaceSpaceSplit(" a")
-	assert.Equal(t, []string{"a"}, actual)
+	assert.Equal(t, nil, adapter.Run(`db migrate --dry-run "add users" --limit 2`))
+	assert.Equal(t, []string{"add users"}, received.Args)
+	assert.Equal(t, &testFlags{DryRun: true, Limit: 2}, received.Flags)
+	assert.Equal(t, []string{}, received.Modules)
+	assert.Equal(t, context.Canceled, received.Context().Err())
 
-	actual = replaceSpaceSplit(" a b ")
-	assert.Equal(t, []string{"a", "b"}, actual)
+	assert.Equal(t, nil, adapter.Run("db", "migrate", "--target", "hello world", "it's"))
+	assert.Equal(t, []string{"it's"}, received.Args)
+	assert.Equal(t, &testFlags{Limit: 10, Name: "hello world"}, received.Flags)
 
-	actual = replaceSpaceSplit(" a  b ")
-	assert.Equal(t, []string{"a", "b"}, actual)
+	err := adapter.Run("db", "check")
+	assert.Equal(t, "cmd: execution failed: db check: schema drift", err.Error())
+	assert.Equal(t, 3, cmd.ExitCode(err))
 }
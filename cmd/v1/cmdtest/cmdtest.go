@@ -0,0 +1,49 @@
+package cmdtest
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/ampliway/way-lib-go/app"
+	"github.com/ampliway/way-lib-go/cmd"
+	cmdV1 "github.com/ampliway/way-lib-go/cmd/v1"
+)
+
+// Result is what a run left behind. Err is the error returned by the run,
+// exit code included.
+type Result struct {
+	Stdout string
+	Stderr string
+	Err    error
+}
+
+func (r *Result) ExitCode() int {
+	return cmd.ExitCode(r.Err)
+}
+
+// Run executes the command given by arguments, a single command line quoted
+// as in a shell or several arguments kept as argv, against app (usually an
+// appV1.Mock) with stdout and stderr captured. The app is not shut down, so it
+// can be inspected afterwards.
+func Run[T any](c *cmdV1.Cmd[T], app app.V1[T], arguments ...string) *Result {
+	return RunStdin(c, app, "", arguments...)
+}
+
+// RunStdin is Run with stdin as the input of the command.
+func RunStdin[T any](c *cmdV1.Cmd[T], app app.V1[T], stdin string, arguments ...string) *Result {
+	stdout := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+
+	err := c.RunSession(&cmdV1.Session[T]{
+		App:    app,
+		Stdin:  strings.NewReader(stdin),
+		Stdout: stdout,
+		Stderr: stderr,
+	}, arguments...)
+
+	return &Result{
+		Stdout: stdout.String(),
+		Stderr: stderr.String(),
+		Err:    err,
+	}
+}
@@ -0,0 +1,152 @@
+package cmdtest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ampliway/way-lib-go/app"
+	appV1 "github.com/ampliway/way-lib-go/app/v1"
+	"github.com/ampliway/way-lib-go/cmd"
+	cmdV1 "github.com/ampliway/way-lib-go/cmd/v1"
+	"github.com/ampliway/way-lib-go/msg"
+	msgV1 "github.com/ampliway/way-lib-go/msg/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+type testConfig struct {
+	Topic string `required:"false" default:"orders"`
+}
+
+type testFlags struct {
+	Count int `required:"false" default:"1"`
+}
+
+func newAdapter(t *testing.T) *cmdV1.Cmd[testConfig] {
+	t.Helper()
+
+	adapter := cmdV1.New[testConfig]()
+	assert.Equal(t, nil, adapter.Add(&cmd.Config[testConfig]{
+		Name:        "publish",
+		Description: "Publish test messages",
+		Usage:       "<key>",
+		Flags:       &testFlags{},
+		Modules:     []string{msg.MODULE_NAME},
+		Execute: func(app app.V1[testConfig], in *cmd.Input) error {
+			if len(in.Args) != 1 {
+				fmt.Fprintln(in.Stderr, "missing key")
+
+				return cmd.Exit(cmd.ExitUsage, nil)
+			}
+
+			for i := 0; i < in.Flags.(*testFlags).Count; i++ {
+				if err := app.Msg().PublishT(app.Config().Topic, in.Args[0], i); err != nil {
+					return err
+				}
+			}
+
+			fmt.Fprintf(in.Stdout, "published %d\n", in.Flags.(*testFlags).Count)
+
+			return nil
+		},
+	}))
+	assert.Equal(t, nil, adapter.Add(&cmd.Config[testConfig]{
+		Name:        "fail",
+		Description: "Always fail",
+		Modules:     []string{},
+		Execute: func(app app.V1[testConfig], in *cmd.Input) error {
+			return cmd.Exit(3, errors.New("broken"))
+		},
+	}))
+
+	return adapter
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+
+	tableTest := []struct {
+		Scenario       string
+		Arguments      string
+		ExpectedStdout string
+		ExpectedStderr string
+		ExpectedErr    string
+		ExpectedCode   int
+	}{
+		{Scenario: "success", Arguments: "publish k1 --count 2", ExpectedStdout: "published 2\n"},
+		{Scenario: "exit_without_message", Arguments: "publish", ExpectedStderr: "missing key\n", ExpectedErr: "cmd: execution failed: publish: exit status 2", ExpectedCode: cmd.ExitUsage},
+		{Scenario: "failure", Arguments: "fail", ExpectedErr: "cmd: execution failed: fail: broken", ExpectedCode: 3},
+		{Scenario: "usage", Arguments: "pub", ExpectedErr: `cmd: unknown command: pub, did you mean "publish"?`, ExpectedCode: cmd.ExitUsage},
+		{Scenario: "empty", Arguments: "", ExpectedErr: "cmd: with empty arguments", ExpectedCode: cmd.ExitUsage},
+		{Scenario: "reserved", Arguments: "commands", ExpectedStdout: "publish        Publish test messages\n"},
+	}
+
+	for _, rowTest := range tableTest {
+		rowTest := rowTest
+
+		t.Run(rowTest.Scenario, func(t *testing.T) {
+			t.Parallel()
+
+			result := Run[testConfig](newAdapter(t), appV1.NewMock[testConfig](nil), rowTest.Arguments)
+
+			if rowTest.Scenario == "reserved" {
+				assert.Contains(t, result.Stdout, rowTest.ExpectedStdout)
+			} else {
+				assert.Equal(t, rowTest.ExpectedStdout, result.Stdout)
+			}
+
+			assert.Equal(t, rowTest.ExpectedStderr, result.Stderr)
+			assert.Equal(t, rowTest.ExpectedCode, result.ExitCode())
+
+			if rowTest.ExpectedErr == "" {
+				assert.Equal(t, nil, result.Err)
+
+				return
+			}
+
+			assert.Equal(t, rowTest.ExpectedErr, result.Err.Error())
+		})
+	}
+}
+
+func TestRun_App(t *testing.T) {
+	t.Parallel()
+
+	mock := appV1.NewMock(&testConfig{Topic: "audit"})
+
+	result := Run[testConfig](newAdapter(t), mock, "publish", "k1")
+	assert.Equal(t, nil, result.Err)
+	assert.Equal(t, []string{msg.MODULE_NAME}, mock.Connected())
+	assert.Equal(t, 0, mock.Shutdowns())
+	assert.Equal(t, []msgV1.MockMessage{{Topic: "audit", Key: "k1", Message: 0}}, mock.Msg().(*msgV1.Mock).Messages())
+}
+
+func TestRun_Argv(t *testing.T) {
+	t.Parallel()
+
+	mock := appV1.NewMock[testConfig](nil)
+
+	for _, key := range []string{"key with spaces", "it's \"quoted\""} {
+		result := Run[testConfig](newAdapter(t), mock, "publish", key)
+		assert.Equal(t, nil, result.Err)
+	}
+
+	messages := mock.Msg().(*msgV1.Mock).Messages()
+	assert.Len(t, messages, 2)
+	assert.Equal(t, "key with spaces", messages[0].Key)
+	assert.Equal(t, "it's \"quoted\"", messages[1].Key)
+}
+
+func TestRunStdin_Shell(t *testing.T) {
+	t.Parallel()
+
+	mock := appV1.NewMock[testConfig](nil)
+	stdin := "publish k1\npublish \\\n  k2 --count 2\nfail\npublish 'k\n3'\nshell\n\nexit\npublish k4\n"
+
+	result := RunStdin[testConfig](newAdapter(t), mock, stdin, "shell")
+	assert.Equal(t, nil, result.Err)
+	assert.Equal(t, "published 1\npublished 2\npublished 1\n", result.Stdout)
+	assert.Equal(t, "cmd: execution failed: fail: broken\ncmd: shell is already running\n", result.Stderr)
+	assert.Len(t, mock.Msg().(*msgV1.Mock).Messages(), 4)
+	assert.Equal(t, "k\n3", mock.Msg().(*msgV1.Mock).Messages()[3].Key)
+}
@@ -0,0 +1,365 @@
+package v1
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/ampliway/way-lib-go/cmd"
+)
+
+const (
+	shellBash = "bash"
+	shellZsh  = "zsh"
+	shellFish = "fish"
+)
+
+var identifierReplacer = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// completionNode is a point of the command tree: every way to type its path,
+// aliases included, and the words that may follow it.
+type completionNode struct {
+	paths []string
+	words []completionWord
+}
+
+type completionWord struct {
+	word        string
+	description string
+	flag        bool
+	value       bool
+}
+
+func completionNodes[T any](configs []*cmd.Config[T]) ([]completionNode, error) {
+	return appendCompletionNodes(nil, []string{""}, configs, nil)
+}
+
+func appendCompletionNodes[T any](nodes []completionNode, paths []string, configs []*cmd.Config[T], flags any) ([]completionNode, error) {
+	vars, err := flagVariables(flags)
+	if err != nil {
+		return nil, err
+	}
+
+	node := completionNode{paths: paths}
+
+	for _, config := range configs {
+		for _, name := range configNames(config) {
+			node.words = append(node.words, completionWord{word: name, description: config.Description})
+		}
+	}
+
+	for _, v := range vars {
+		node.words = append(node.words, completionWord{word: flagName(v.Name), description: v.Description, flag: true, value: !isBoolFlag(v)})
+	}
+
+	node.words = append(node.words, completionWord{word: helpFlag, description: "Show help", flag: true})
+	nodes = append(nodes, node)
+
+	for _, config := range configs {
+		childPaths := []string{}
+
+		for _, path := range paths {
+			for _, name := range configNames(config) {
+				childPaths = append(childPaths, strings.TrimSpace(path+" "+name))
+			}
+		}
+
+		nodes, err = appendCompletionNodes(nodes, childPaths, config.Commands, config.Flags)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nodes, nil
+}
+
+// writeCompletion prints a completion script for program. The scripts rebuild
+// the command path from the words typed so far: flags are skipped with their
+// value and the first positional arg ends the path, after which only flags are
+// offered. Nothing is offered for a flag value or after "--".
+func writeCompletion(w io.Writer, program, shell string, nodes []completionNode) error {
+	function := "_" + identifierReplacer.ReplaceAllString(program, "_") + "_complete"
+
+	var script string
+
+	switch shell {
+	case shellBash:
+		script = bashCompletion(program, function, nodes)
+	case shellZsh:
+		script = zshCompletion(program, function, nodes)
+	case shellFish:
+		script = fishCompletion(program, function, nodes)
+	default:
+		return fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errUnknownShell, shell)
+	}
+
+	_, err := io.WriteString(w, script)
+
+	return err
+}
+
+// commandPaths lists every way to type a command path.
+func commandPaths(nodes []completionNode) []string {
+	result := []string{}
+
+	for _, node := range nodes {
+		for _, path := range node.paths {
+			if path != "" {
+				result = append(result, path)
+			}
+		}
+	}
+
+	return result
+}
+
+// valueFlags lists the flags taking a value as "<path> <flag>", the global
+// --output flag matching under any path.
+func valueFlags(nodes []completionNode) []string {
+	result := []string{}
+
+	for _, node := range nodes {
+		for _, word := range node.words {
+			if !word.flag || !word.value {
+				continue
+			}
+
+			for _, path := range node.paths {
+				result = append(result, path+" "+word.word)
+			}
+		}
+	}
+
+	return result
+}
+
+// splitWords separates the subcommands of a node from its flags.
+func splitWords(words []completionWord) ([]completionWord, []completionWord) {
+	commands, flags := []completionWord{}, []completionWord{}
+
+	for _, word := range words {
+		if word.flag {
+			flags = append(flags, word)
+		} else {
+			commands = append(commands, word)
+		}
+	}
+
+	return commands, flags
+}
+
+func joinWords(words []completionWord) string {
+	result := make([]string, 0, len(words))
+	for _, word := range words {
+		result = append(result, word.word)
+	}
+
+	return strings.Join(result, " ")
+}
+
+// writeShellHelpers prints the bash and zsh functions telling whether a word
+// extends the command path and whether a flag takes a value.
+func writeShellHelpers(b *strings.Builder, function string, nodes []completionNode) {
+	fmt.Fprintf(b, "%s_is_path() {\n", function)
+	fmt.Fprintf(b, "    case \"$1\" in\n        %s) return 0 ;;\n    esac\n", shellPatterns(commandPaths(nodes)))
+	b.WriteString("    return 1\n")
+	b.WriteString("}\n")
+	fmt.Fprintf(b, "%s_takes_value() {\n", function)
+	fmt.Fprintf(b, "    case \"$1 $2\" in\n        *\" %s\"|%s) return 0 ;;\n    esac\n", outputFlag, shellPatterns(valueFlags(nodes)))
+	b.WriteString("    return 1\n")
+	b.WriteString("}\n")
+}
+
+func bashCompletion(program, function string, nodes []completionNode) string {
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "# bash completion for %s\n", program)
+	writeShellHelpers(b, function, nodes)
+	fmt.Fprintf(b, "%s() {\n", function)
+	b.WriteString("    local cur=\"${COMP_WORDS[COMP_CWORD]}\" path=\"\" word commands=\"\" flags=\"\" positional=\"\" i\n")
+	b.WriteString("    for ((i = 1; i < COMP_CWORD; i++)); do\n")
+	b.WriteString("        word=\"${COMP_WORDS[i]}\"\n")
+	b.WriteString("        case \"$word\" in\n")
+	b.WriteString("            --) return ;;\n")
+	b.WriteString("            -*=*) ;;\n")
+	b.WriteString("            -*)\n")
+	b.WriteString("                if [[ \"${COMP_WORDS[i+1]}\" == \"=\" ]]; then\n")
+	b.WriteString("                    ((i += 2))\n")
+	fmt.Fprintf(b, "                elif %s_takes_value \"$path\" \"$word\"; then\n", function)
+	b.WriteString("                    ((i++))\n")
+	b.WriteString("                fi ;;\n")
+	b.WriteString("            *)\n")
+	fmt.Fprintf(b, "                if [[ -z \"$positional\" ]] && %s_is_path \"${path:+$path }$word\"; then\n", function)
+	b.WriteString("                    path=\"${path:+$path }$word\"\n")
+	b.WriteString("                else\n")
+	b.WriteString("                    positional=1\n")
+	b.WriteString("                fi ;;\n")
+	b.WriteString("        esac\n")
+	b.WriteString("    done\n")
+	b.WriteString("    ((i > COMP_CWORD)) && return\n")
+	b.WriteString("    case \"$path\" in\n")
+
+	for _, node := range nodes {
+		commands, flags := splitWords(node.words)
+
+		fmt.Fprintf(b, "        %s) commands=%s flags=%s ;;\n", shellPatterns(node.paths), shellQuote(joinWords(commands)), shellQuote(joinWords(flags)))
+	}
+
+	b.WriteString("    esac\n")
+	b.WriteString("    [[ -n \"$positional\" ]] && commands=\"\"\n")
+	b.WriteString("    COMPREPLY=($(compgen -W \"$commands $flags\" -- \"$cur\"))\n")
+	b.WriteString("}\n")
+	fmt.Fprintf(b, "complete -F %s %s\n", function, shellQuote(program))
+
+	return b.String()
+}
+
+func zshCompletion(program, function string, nodes []completionNode) string {
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "#compdef %s\n", program)
+	writeShellHelpers(b, function, nodes)
+	fmt.Fprintf(b, "%s() {\n", function)
+	b.WriteString("    local path_=\"\" word positional=\"\" i\n")
+	b.WriteString("    local -a commands flags\n")
+	b.WriteString("    for ((i = 2; i < CURRENT; i++)); do\n")
+	b.WriteString("        word=\"${words[i]}\"\n")
+	b.WriteString("        case \"$word\" in\n")
+	b.WriteString("            --) return ;;\n")
+	b.WriteString("            -*=*) ;;\n")
+	fmt.Fprintf(b, "            -*) %s_takes_value \"$path_\" \"$word\" && ((i++)) ;;\n", function)
+	b.WriteString("            *)\n")
+	fmt.Fprintf(b, "                if [[ -z \"$positional\" ]] && %s_is_path \"${path_:+$path_ }$word\"; then\n", function)
+	b.WriteString("                    path_=\"${path_:+$path_ }$word\"\n")
+	b.WriteString("                else\n")
+	b.WriteString("                    positional=1\n")
+	b.WriteString("                fi ;;\n")
+	b.WriteString("        esac\n")
+	b.WriteString("    done\n")
+	b.WriteString("    ((i > CURRENT)) && return\n")
+	b.WriteString("    case \"$path_\" in\n")
+
+	for _, node := range nodes {
+		commands, flags := splitWords(node.words)
+
+		fmt.Fprintf(b, "        %s) commands=(%s) flags=(%s) ;;\n", shellPatterns(node.paths), zshCandidates(commands), zshCandidates(flags))
+	}
+
+	b.WriteString("    esac\n")
+	b.WriteString("    [[ -n \"$positional\" ]] && commands=()\n")
+	b.WriteString("    _describe 'command' commands -- flags\n")
+	b.WriteString("}\n")
+	fmt.Fprintf(b, "compdef %s %s\n", function, shellQuote(program))
+
+	return b.String()
+}
+
+func zshCandidates(words []completionWord) string {
+	result := make([]string, 0, len(words))
+	for _, word := range words {
+		result = append(result, shellQuote(strings.ReplaceAll(word.word, ":", "\\:")+":"+word.description))
+	}
+
+	return strings.Join(result, " ")
+}
+
+// fishCompletion prints a state function echoing the command path and 0 while
+// on it, 1 after a positional arg and 2 after "--". Subcommands are offered in
+// state 0 only, flags in states 0 and 1.
+func fishCompletion(program, function string, nodes []completionNode) string {
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "# fish completion for %s\n", program)
+	fmt.Fprintf(b, "function %s_takes_value\n", function)
+	b.WriteString("    switch \"$argv[1] $argv[2]\"\n")
+	fmt.Fprintf(b, "        case %s\n", strings.Join(quotePaths(append([]string{"* " + outputFlag}, valueFlags(nodes)...)), " "))
+	b.WriteString("            return 0\n")
+	b.WriteString("    end\n")
+	b.WriteString("    return 1\n")
+	b.WriteString("end\n")
+	fmt.Fprintf(b, "function %s_state\n", function)
+	b.WriteString("    set -l words (commandline -opc)\n")
+	b.WriteString("    set -e words[1]\n")
+	b.WriteString("    set -l path \"\"\n")
+	b.WriteString("    set -l state 0\n")
+	b.WriteString("    set -l skip 0\n")
+	b.WriteString("    for word in $words\n")
+	b.WriteString("        if test $skip = 1\n")
+	b.WriteString("            set skip 0\n")
+	b.WriteString("            continue\n")
+	b.WriteString("        end\n")
+	b.WriteString("        switch $word\n")
+	b.WriteString("            case --\n")
+	b.WriteString("                set state 2\n")
+	b.WriteString("                break\n")
+	b.WriteString("            case '-*=*'\n")
+	b.WriteString("            case '-*'\n")
+	fmt.Fprintf(b, "                %s_takes_value \"$path\" $word; and set skip 1\n", function)
+	b.WriteString("            case '*'\n")
+	b.WriteString("                set -l next (string trim -- \"$path $word\")\n")
+	fmt.Fprintf(b, "                if test $state = 0; and contains -- $next %s\n", strings.Join(quotePaths(commandPaths(nodes)), " "))
+	b.WriteString("                    set path $next\n")
+	b.WriteString("                else\n")
+	b.WriteString("                    set state 1\n")
+	b.WriteString("                end\n")
+	b.WriteString("        end\n")
+	b.WriteString("    end\n")
+	b.WriteString("    echo $path\n")
+	b.WriteString("    echo $state\n")
+	b.WriteString("end\n")
+	fmt.Fprintf(b, "function %s_path_is\n", function)
+	fmt.Fprintf(b, "    set -l state (%s_state)\n", function)
+	b.WriteString("    test \"$state[2]\" = 0; and contains -- \"$state[1]\" $argv\n")
+	b.WriteString("end\n")
+	fmt.Fprintf(b, "function %s_flag_path_is\n", function)
+	fmt.Fprintf(b, "    set -l state (%s_state)\n", function)
+	b.WriteString("    test \"$state[2]\" != 2; and contains -- \"$state[1]\" $argv\n")
+	b.WriteString("end\n")
+	fmt.Fprintf(b, "complete -c %s -f\n", shellQuote(program))
+
+	for _, node := range nodes {
+		paths := strings.Join(quotePaths(node.paths), " ")
+
+		for _, word := range node.words {
+			if word.flag {
+				required := ""
+				if word.value {
+					required = " -r"
+				}
+
+				fmt.Fprintf(b, "complete -c %s -n %s -l %s%s -d %s\n",
+					shellQuote(program), shellQuote(function+"_flag_path_is "+paths), strings.TrimPrefix(word.word, flagPrefix), required, shellQuote(word.description))
+
+				continue
+			}
+
+			fmt.Fprintf(b, "complete -c %s -n %s -a %s -d %s\n",
+				shellQuote(program), shellQuote(function+"_path_is "+paths), shellQuote(word.word), shellQuote(word.description))
+		}
+	}
+
+	return b.String()
+}
+
+// shellPatterns joins the quoted paths as alternatives of a case pattern.
+func shellPatterns(paths []string) string {
+	return strings.Join(quotePaths(paths), "|")
+}
+
+func quotePaths(paths []string) []string {
+	quoted := make([]string, 0, len(paths))
+	for _, path := range paths {
+		quoted = append(quoted, doubleQuote(path))
+	}
+
+	return quoted
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func doubleQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "$", `\$`, "`", "\\`").Replace(value) + `"`
+}
@@ -0,0 +1,70 @@
+package v1
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompletionNodes(t *testing.T) {
+	t.Parallel()
+
+	nodes, err := completionNodes(newHelpAdapter(t).configs)
+	assert.Equal(t, nil, err)
+	assert.Len(t, nodes, 3)
+
+	assert.Equal(t, []string{""}, nodes[0].paths)
+	assert.Equal(t, []completionWord{
+		{word: "topics", description: "Topic commands"},
+		{word: "t", description: "Topic commands"},
+		{word: "--help", description: "Show help", flag: true},
+	}, nodes[0].words)
+
+	assert.Equal(t, []string{"topics", "t"}, nodes[1].paths)
+	assert.Equal(t, []string{"topics list", "topics ls", "t list", "t ls"}, nodes[2].paths)
+	assert.Equal(t, []completionWord{
+		{word: "--limit", description: "Max topics listed", flag: true, value: true},
+		{word: "--dry-run", flag: true},
+		{word: "--filter", description: "Name prefix", flag: true, value: true},
+		{word: "--help", description: "Show help", flag: true},
+	}, nodes[2].words)
+}
+
+func TestWriteCompletion(t *testing.T) {
+	t.Parallel()
+
+	nodes, err := completionNodes(newHelpAdapter(t).configs)
+	assert.Equal(t, nil, err)
+
+	output := &bytes.Buffer{}
+	assert.Equal(t, nil, writeCompletion(output, "my-tool", shellBash, nodes))
+	assert.Contains(t, output.String(), "_my_tool_complete() {\n")
+	assert.Contains(t, output.String(), `        "topics"|"t"|"topics list"|"topics ls"|"t list"|"t ls") return 0 ;;`)
+	assert.Contains(t, output.String(), `        *" --output"|"topics list --limit"|"topics ls --limit"|"t list --limit"|"t ls --limit"|"topics list --filter"|`)
+	assert.Contains(t, output.String(), `        "topics list"|"topics ls"|"t list"|"t ls") commands='' flags='--limit --dry-run --filter --help' ;;`)
+	assert.Contains(t, output.String(), "complete -F _my_tool_complete 'my-tool'\n")
+
+	output.Reset()
+	assert.Equal(t, nil, writeCompletion(output, "my-tool", shellZsh, nodes))
+	assert.Contains(t, output.String(), "#compdef my-tool\n")
+	assert.Contains(t, output.String(), `        "") commands=('topics:Topic commands' 't:Topic commands') flags=('--help:Show help') ;;`)
+	assert.Contains(t, output.String(), `            -*) _my_tool_complete_takes_value "$path_" "$word" && ((i++)) ;;`)
+
+	output.Reset()
+	assert.Equal(t, nil, writeCompletion(output, "my-tool", shellFish, nodes))
+	assert.Contains(t, output.String(), `        case "* --output" "topics list --limit" "topics ls --limit"`)
+	assert.Contains(t, output.String(),
+		`complete -c 'my-tool' -n '_my_tool_complete_flag_path_is "topics list" "topics ls" "t list" "t ls"' -l limit -r -d 'Max topics listed'`)
+	assert.Contains(t, output.String(), `complete -c 'my-tool' -n '_my_tool_complete_path_is "topics" "t"' -a 'list' -d 'List topics'`)
+
+	err = writeCompletion(output, "my-tool", "tcsh", nodes)
+	assert.Equal(t, "cmd: unknown shell, expected bash, zsh or fish: tcsh", err.Error())
+}
+
+func TestShellQuote(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+	assert.Equal(t, `"a \"b\" \$c"`, doubleQuote(`a "b" $c`))
+}
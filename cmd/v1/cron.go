@@ -0,0 +1,193 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ampliway/way-lib-go/cmd"
+)
+
+const cronSearchYears = 5
+
+// cronBits has bit n set when value n is allowed.
+type cronBits uint64
+
+func (b cronBits) has(n int) bool {
+	return b&(1<<uint(n)) != 0
+}
+
+type cronRange struct {
+	min, max int
+	names    []string
+}
+
+var (
+	cronMinute = cronRange{min: 0, max: 59}
+	cronHour   = cronRange{min: 0, max: 23}
+	cronDay    = cronRange{min: 1, max: 31}
+	cronMonth  = cronRange{min: 1, max: 12, names: []string{"", "jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}}
+	cronWeek   = cronRange{min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}}
+
+	cronDescriptors = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// cronSchedule is a standard five field cron expression: minute, hour, day of
+// month, month and day of week. As in cron, when both days are restricted a
+// time matching either of them is due.
+type cronSchedule struct {
+	minute, hour, day, month, week cronBits
+	anyDay, anyWeek                bool
+}
+
+func parseCron(expr string) (*cronSchedule, error) {
+	if descriptor, exist := cronDescriptors[strings.TrimSpace(expr)]; exist {
+		expr = descriptor
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%s: %w: %q: expected 5 fields", cmd.MODULE_NAME, errCronExpr, expr)
+	}
+
+	s := &cronSchedule{
+		anyDay:  strings.HasPrefix(fields[2], "*"),
+		anyWeek: strings.HasPrefix(fields[4], "*"),
+	}
+
+	for i, target := range []struct {
+		bits  *cronBits
+		field cronRange
+	}{
+		{bits: &s.minute, field: cronMinute},
+		{bits: &s.hour, field: cronHour},
+		{bits: &s.day, field: cronDay},
+		{bits: &s.month, field: cronMonth},
+		{bits: &s.week, field: cronWeek},
+	} {
+		bits, err := parseCronField(fields[i], target.field)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w: %q: %w", cmd.MODULE_NAME, errCronExpr, expr, err)
+		}
+
+		*target.bits = bits
+	}
+
+	if s.week.has(7) {
+		s.week |= 1
+	}
+
+	return s, nil
+}
+
+// parseCronField reads a comma separated list of "*", "n", "n-m", each with
+// an optional "/step".
+func parseCronField(field string, r cronRange) (cronBits, error) {
+	var bits cronBits
+
+	for _, item := range strings.Split(field, ",") {
+		span, stepText, hasStep := strings.Cut(item, "/")
+
+		step := 1
+		if hasStep {
+			var err error
+
+			step, err = strconv.Atoi(stepText)
+			if err != nil || step <= 0 {
+				return 0, fmt.Errorf("invalid step: %s", item)
+			}
+		}
+
+		low, high := r.min, r.max
+
+		if span != "*" {
+			lowText, highText, isRange := strings.Cut(span, "-")
+
+			var err error
+
+			low, err = r.value(lowText)
+			if err != nil {
+				return 0, err
+			}
+
+			high = low
+			if isRange {
+				high, err = r.value(highText)
+				if err != nil {
+					return 0, err
+				}
+			} else if hasStep {
+				high = r.max
+			}
+
+			if high < low {
+				return 0, fmt.Errorf("invalid range: %s", span)
+			}
+		}
+
+		for n := low; n <= high; n += step {
+			bits |= 1 << uint(n)
+		}
+	}
+
+	return bits, nil
+}
+
+func (r cronRange) value(text string) (int, error) {
+	for i, name := range r.names {
+		if name != "" && strings.EqualFold(name, text) {
+			return i, nil
+		}
+	}
+
+	n, err := strconv.Atoi(text)
+	if err != nil || n < r.min || n > r.max {
+		return 0, fmt.Errorf("value out of range %d-%d: %s", r.min, r.max, text)
+	}
+
+	return n, nil
+}
+
+// next returns the first due time strictly after t, in the location of t, or
+// the zero time when none comes within a few years (like "0 0 30 2 *").
+func (s *cronSchedule) next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Year() + cronSearchYears
+
+	for t.Year() <= limit {
+		switch {
+		case !s.month.has(int(t.Month())):
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !s.hour.has(t.Hour()):
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !s.minute.has(t.Minute()):
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+
+	return time.Time{}
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	day := s.day.has(t.Day())
+	week := s.week.has(int(t.Weekday()))
+
+	if s.anyDay || s.anyWeek {
+		return day && week
+	}
+
+	return day || week
+}
@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCron_Errors(t *testing.T) {
+	t.Parallel()
+
+	tableTest := []struct {
+		Expr        string
+		ExpectedErr string
+	}{
+		{Expr: "* * * *", ExpectedErr: `cmd: invalid cron expression: "* * * *": expected 5 fields`},
+		{Expr: "60 * * * *", ExpectedErr: `cmd: invalid cron expression: "60 * * * *": value out of range 0-59: 60`},
+		{Expr: "*/0 * * * *", ExpectedErr: `cmd: invalid cron expression: "*/0 * * * *": invalid step: */0`},
+		{Expr: "* 5-2 * * *", ExpectedErr: `cmd: invalid cron expression: "* 5-2 * * *": invalid range: 5-2`},
+		{Expr: "* * * foo *", ExpectedErr: `cmd: invalid cron expression: "* * * foo *": value out of range 1-12: foo`},
+		{Expr: "@often", ExpectedErr: `cmd: invalid cron expression: "@often": expected 5 fields`},
+	}
+
+	for _, rowTest := range tableTest {
+		_, err := parseCron(rowTest.Expr)
+		assert.Equal(t, rowTest.ExpectedErr, err.Error())
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	t.Parallel()
+
+	// Tuesday.
+	from := time.Date(2023, 8, 1, 10, 17, 42, 0, time.UTC)
+
+	tableTest := []struct {
+		Expr     string
+		Expected time.Time
+	}{
+		{Expr: "* * * * *", Expected: time.Date(2023, 8, 1, 10, 18, 0, 0, time.UTC)},
+		{Expr: "0 * * * *", Expected: time.Date(2023, 8, 1, 11, 0, 0, 0, time.UTC)},
+		{Expr: "@hourly", Expected: time.Date(2023, 8, 1, 11, 0, 0, 0, time.UTC)},
+		{Expr: "*/15 * * * *", Expected: time.Date(2023, 8, 1, 10, 30, 0, 0, time.UTC)},
+		{Expr: "5,50 9-17 * * *", Expected: time.Date(2023, 8, 1, 10, 50, 0, 0, time.UTC)},
+		{Expr: "30 2 * * *", Expected: time.Date(2023, 8, 2, 2, 30, 0, 0, time.UTC)},
+		{Expr: "0 0 * * sun", Expected: time.Date(2023, 8, 6, 0, 0, 0, 0, time.UTC)},
+		{Expr: "0 0 * * 7", Expected: time.Date(2023, 8, 6, 0, 0, 0, 0, time.UTC)},
+		{Expr: "0 0 1 jan *", Expected: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Expr: "0 0 15 * fri", Expected: time.Date(2023, 8, 4, 0, 0, 0, 0, time.UTC)},
+		{Expr: "0 0 */10 * *", Expected: time.Date(2023, 8, 11, 0, 0, 0, 0, time.UTC)},
+		{Expr: "0 12 29 2 *", Expected: time.Date(2024, 2, 29, 12, 0, 0, 0, time.UTC)},
+		{Expr: "0 0 30 2 *", Expected: time.Time{}},
+	}
+
+	for _, rowTest := range tableTest {
+		schedule, err := parseCron(rowTest.Expr)
+		assert.Equal(t, nil, err)
+		assert.Equal(t, rowTest.Expected, schedule.next(from), rowTest.Expr)
+	}
+}
@@ -6,7 +6,6 @@ import (
 )
 
 var (
-	errConfigLen              = fmt.Errorf("configs must be equal or less than %v", configMaxLen)
 	errConfigAlreadyExist     = errors.New("config already exist")
 	errConfigNil              = errors.New("config cannot be nil")
 	errConfigNameEmpty        = errors.New("config name cannot be empty")
@@ -14,7 +13,41 @@ var (
 	errConfigDescriptionEmpty = errors.New("config description cannot be empty")
 	errConfigDescriptionLen   = fmt.Errorf("config description cannot be more than %v", configDescriptionMaxLen)
 	errConfigExecuteNil       = errors.New("config execute cannot be nil")
+	errConfigAliasEmpty       = errors.New("config alias cannot be empty")
+	errMissingSubcommand      = errors.New("missing subcommand")
+	errConfigFlags            = errors.New("config flags must be a pointer to a struct")
+	errUnterminatedQuote      = errors.New("unterminated quote")
+	errUnknownFlag            = errors.New("unknown flag")
+	errFlagValue              = errors.New("flag needs a value")
+	errFlagMissing            = errors.New("flag not set")
+	errFlagRepeated           = errors.New("flag given more than once")
+	errConfigArgPosition      = errors.New("config argument must come before the command, or after \"--\" as an arg")
+	errInvalidFlags           = errors.New("invalid flags")
+	errUnknownShell           = errors.New("unknown shell, expected bash, zsh or fish")
+	errInterrupted            = errors.New("interrupted")
+	errGracePeriod            = errors.New("command did not return within the grace period")
+	errForced                 = errors.New("command abandoned on second signal")
+	errShutdown               = errors.New("shutdown failed")
+	errConfigExecuteWorkers   = errors.New("config cannot have both execute and workers")
+	errWorkerNil              = errors.New("worker cannot be nil")
+	errWorkerCritical         = errors.New("critical worker failed")
+	errWorkerPanic            = errors.New("worker panicked")
+	errWorkerStop             = errors.New("worker stop failed")
+	errWorkerStuck            = errors.New("workers did not stop within the grace period")
+	errWorkerStopStuck        = errors.New("worker stop did not return within the grace period")
+	errPanic                  = errors.New("command panicked")
+	errAudit                  = errors.New("audit publish failed")
 	errEmptyArguments         = errors.New("with empty arguments")
 	errUnknown                = errors.New("unknown command")
 	errExecutionFailed        = errors.New("execution failed")
+	errUnknownFormat          = errors.New("unknown output format")
+	errShellNested            = errors.New("shell is already running")
+	errCronExpr               = errors.New("invalid cron expression")
+	errScheduleUsage          = errors.New("usage: schedule <cron> <command> [<cron> <command>]...")
+	errScheduleCommand        = errors.New("only commands with execute can be scheduled")
+	errScheduleEmpty          = errors.New("no job scheduled")
+	errScheduleLock           = errors.New("schedule lock failed")
+	errScheduleLocker         = errors.New("cache does not implement cache.Locker")
+	errConfigFlagReserved     = errors.New("config flag is reserved")
+	errCryptUsage             = errors.New("usage: config-crypt keygen <key-file> | encrypt < value | rotate <new-key-file> <file>...")
 )
@@ -0,0 +1,140 @@
+package v1
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/ampliway/way-lib-go/cmd"
+	configV1 "github.com/ampliway/way-lib-go/config/v1"
+)
+
+const (
+	helpFlag      = "--help"
+	helpShortFlag = "-h"
+)
+
+// wantsHelp reports whether --help or -h was typed before a "--" terminator.
+func wantsHelp(args []string) bool {
+	for _, arg := range args {
+		switch arg {
+		case flagTerminator:
+			return false
+		case helpFlag, helpShortFlag:
+			return true
+		}
+	}
+
+	return false
+}
+
+func writeHelp[T any](w io.Writer, program string, configs []*cmd.Config[T]) error {
+	fmt.Fprintf(w, "Usage:\n  %s <command> [flags] [args]\n\n", program)
+
+	writeCommands(w, configs)
+
+	fmt.Fprintf(w, "\nGlobal flags:\n  %s %s|%s|%s  Format of the results (default %s)\n", outputFlag, cmd.OutputTable, cmd.OutputJSON, cmd.OutputYAML, cmd.OutputTable)
+
+	_, err := fmt.Fprintf(w, "\nRun \"%s help <command>\" for more information about a command.\n", program)
+
+	return err
+}
+
+func writeCommandHelp[T any](w io.Writer, program string, path []string, config *cmd.Config[T]) error {
+	vars, err := flagVariables(config.Flags)
+	if err != nil {
+		return err
+	}
+
+	usage := append([]string{program}, path...)
+	if len(config.Commands) > 0 {
+		usage = append(usage, "<command>")
+	}
+
+	if len(vars) > 0 {
+		usage = append(usage, "[flags]")
+	}
+
+	if config.Usage != "" {
+		usage = append(usage, config.Usage)
+	}
+
+	fmt.Fprintf(w, "Usage:\n  %s\n\n%s\n", strings.Join(usage, " "), config.Description)
+
+	if len(config.Aliases) > 0 {
+		fmt.Fprintf(w, "\nAliases:\n  %s\n", strings.Join(config.Aliases, ", "))
+	}
+
+	if len(config.Commands) > 0 {
+		fmt.Fprintln(w)
+		writeCommands(w, config.Commands)
+	}
+
+	if len(vars) > 0 {
+		fmt.Fprintln(w, "\nFlags:")
+
+		width := 0
+		for _, v := range vars {
+			if len(flagUsage(v)) > width {
+				width = len(flagUsage(v))
+			}
+		}
+
+		for _, v := range vars {
+			fmt.Fprintln(w, strings.TrimRight(fmt.Sprintf("  %-*s  %s", width, flagUsage(v), flagDetails(v)), " "))
+		}
+	}
+
+	if len(config.Examples) > 0 {
+		fmt.Fprintln(w, "\nExamples:")
+
+		for _, example := range config.Examples {
+			fmt.Fprintf(w, "  %s\n", example)
+		}
+	}
+
+	return nil
+}
+
+func writeCommands[T any](w io.Writer, configs []*cmd.Config[T]) {
+	fmt.Fprintln(w, "Commands:")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, config := range configs {
+		fmt.Fprintf(tw, "  %s\t%s\n", strings.Join(configNames(config), ", "), config.Description)
+	}
+
+	tw.Flush()
+}
+
+func flagUsage(v configV1.Variable) string {
+	if isBoolFlag(v) {
+		return flagName(v.Name)
+	}
+
+	return flagName(v.Name) + " " + strings.TrimPrefix(v.Type, "*")
+}
+
+func flagDetails(v configV1.Variable) string {
+	details := []string{}
+
+	switch {
+	case v.HasDefault && v.Default != "":
+		details = append(details, "default "+v.Default)
+	case v.Required && !v.HasDefault:
+		details = append(details, "required")
+	}
+
+	details = append(details, v.Rules...)
+
+	if len(details) == 0 {
+		return v.Description
+	}
+
+	return strings.TrimSpace(fmt.Sprintf("%s (%s)", v.Description, strings.Join(details, ", ")))
+}
+
+func isBoolFlag(v configV1.Variable) bool {
+	return strings.TrimPrefix(v.Type, "*") == "bool"
+}
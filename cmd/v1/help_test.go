@@ -0,0 +1,121 @@
+package v1
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ampliway/way-lib-go/app"
+	"github.com/ampliway/way-lib-go/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+type testListFlags struct {
+	Limit  int    `default:"10" min:"1" desc:"Max topics listed"`
+	DryRun bool   `required:"false"`
+	Filter string `desc:"Name prefix"`
+}
+
+func newHelpAdapter(t *testing.T) *Cmd[testConfig] {
+	t.Helper()
+
+	execute := func(app app.V1[testConfig], in *cmd.Input) error {
+		return nil
+	}
+
+	adapter := New[testConfig]()
+	adapter.program = "tool"
+	adapter.configs = adapter.configs[:0]
+
+	assert.Equal(t, nil, adapter.Add(&cmd.Config[testConfig]{
+		Name:        "topics",
+		Aliases:     []string{"t"},
+		Description: "Topic commands",
+		Commands: []*cmd.Config[testConfig]{
+			{
+				Name:        "list",
+				Aliases:     []string{"ls"},
+				Description: "List topics",
+				Usage:       "[cluster]",
+				Examples:    []string{"tool topics list --limit 5 main"},
+				Flags:       &testListFlags{},
+				Execute:     execute,
+			},
+		},
+	}))
+
+	return adapter
+}
+
+func TestWantsHelp(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, wantsHelp([]string{"a", "--help"}))
+	assert.True(t, wantsHelp([]string{"-h"}))
+	assert.False(t, wantsHelp([]string{"a", "--", "--help"}))
+	assert.False(t, wantsHelp([]string{}))
+}
+
+func TestHelp(t *testing.T) {
+	t.Parallel()
+
+	adapter := newHelpAdapter(t)
+	output := &bytes.Buffer{}
+
+	assert.Equal(t, nil, adapter.help(output, []string{}))
+	assert.Equal(t, ""+
+		"Usage:\n"+
+		"  tool <command> [flags] [args]\n"+
+		"\n"+
+		"Commands:\n"+
+		"  topics, t  Topic commands\n"+
+		"\n"+
+		"Global flags:\n"+
+		"  --output table|json|yaml  Format of the results (default table)\n"+
+		"\n"+
+		"Run \"tool help <command>\" for more information about a command.\n", output.String())
+
+	output.Reset()
+	assert.Equal(t, nil, adapter.help(output, []string{"t"}))
+	assert.Equal(t, ""+
+		"Usage:\n"+
+		"  tool topics <command>\n"+
+		"\n"+
+		"Topic commands\n"+
+		"\n"+
+		"Aliases:\n"+
+		"  t\n"+
+		"\n"+
+		"Commands:\n"+
+		"  list, ls  List topics\n", output.String())
+
+	output.Reset()
+	assert.Equal(t, nil, adapter.help(output, []string{"topics", "ls"}))
+	assert.Equal(t, ""+
+		"Usage:\n"+
+		"  tool topics list [flags] [cluster]\n"+
+		"\n"+
+		"List topics\n"+
+		"\n"+
+		"Aliases:\n"+
+		"  ls\n"+
+		"\n"+
+		"Flags:\n"+
+		"  --limit int      Max topics listed (default 10, min=1)\n"+
+		"  --dry-run\n"+
+		"  --filter string  Name prefix (required)\n"+
+		"\n"+
+		"Examples:\n"+
+		"  tool topics list --limit 5 main\n", output.String())
+
+	err := adapter.help(output, []string{"topics", "create"})
+	assert.Equal(t, "cmd: unknown command: [topics create]", err.Error())
+}
+
+func TestCommandRows(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []commandRow{
+		{Command: "topics", Description: "Topic commands"},
+		{Command: "topics list", Description: "List topics"},
+	}, commandRows(newHelpAdapter(t).configs))
+}
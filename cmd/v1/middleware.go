@@ -0,0 +1,257 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/user"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ampliway/way-lib-go/app"
+	"github.com/ampliway/way-lib-go/cmd"
+	configV1 "github.com/ampliway/way-lib-go/config/v1"
+	"github.com/ampliway/way-lib-go/msg"
+)
+
+const (
+	logEventStart = "command.start"
+	logEventEnd   = "command.end"
+
+	redactedValue = "******"
+)
+
+// AuditEvent is published by the Audit middleware after every command.
+type AuditEvent struct {
+	ID         string            `json:"id"`
+	Command    string            `json:"command"`
+	Args       []string          `json:"args"`
+	Flags      map[string]string `json:"flags,omitempty"`
+	User       string            `json:"user"`
+	Host       string            `json:"host"`
+	StartedAt  time.Time         `json:"started_at"`
+	DurationMs int64             `json:"duration_ms"`
+	Error      string            `json:"error,omitempty"`
+}
+
+type logEntry struct {
+	Time       time.Time         `json:"time"`
+	Event      string            `json:"event"`
+	Command    string            `json:"command"`
+	Args       []string          `json:"args,omitempty"`
+	Flags      map[string]string `json:"flags,omitempty"`
+	DurationMs *int64            `json:"duration_ms,omitempty"`
+	Error      string            `json:"error,omitempty"`
+}
+
+// Use adds middleware around every command; the first one given is the
+// outermost.
+func (c *Cmd[T]) Use(middleware ...cmd.Middleware[T]) {
+	c.middleware = append(c.middleware, middleware...)
+}
+
+func (c *Cmd[T]) chain(handler cmd.Handler[T]) cmd.Handler[T] {
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		handler = c.middleware[i](handler)
+	}
+
+	return handler
+}
+
+// Hooks runs before ahead of the command, which is skipped if it fails, and
+// after once it returns, with the error it returned. Either may be nil.
+func Hooks[T any](before func(app app.V1[T], in *cmd.Input) error, after func(app app.V1[T], in *cmd.Input, err error) error) cmd.Middleware[T] {
+	return func(next cmd.Handler[T]) cmd.Handler[T] {
+		return func(app app.V1[T], in *cmd.Input) error {
+			if before != nil {
+				if err := before(app, in); err != nil {
+					return err
+				}
+			}
+
+			err := next(app, in)
+
+			if after != nil {
+				return after(app, in, err)
+			}
+
+			return err
+		}
+	}
+}
+
+// Timing reports how long each command took.
+func Timing[T any](observe func(in *cmd.Input, elapsed time.Duration, err error)) cmd.Middleware[T] {
+	return func(next cmd.Handler[T]) cmd.Handler[T] {
+		return func(app app.V1[T], in *cmd.Input) error {
+			started := time.Now()
+			err := next(app, in)
+			observe(in, time.Since(started), err)
+
+			return err
+		}
+	}
+}
+
+// Recover turns a panic in the command into an error carrying its stack.
+func Recover[T any]() cmd.Middleware[T] {
+	return func(next cmd.Handler[T]) cmd.Handler[T] {
+		return func(app app.V1[T], in *cmd.Input) (err error) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					err = fmt.Errorf("%s: %w: %v\n%s", cmd.MODULE_NAME, errPanic, recovered, debug.Stack())
+				}
+			}()
+
+			return next(app, in)
+		}
+	}
+}
+
+// Logging writes a JSON line when each command starts and ends, with its args
+// and flags; secrets are masked.
+func Logging[T any](w io.Writer) cmd.Middleware[T] {
+	mux := sync.Mutex{}
+	encoder := json.NewEncoder(w)
+
+	write := func(entry logEntry) {
+		mux.Lock()
+		defer mux.Unlock()
+
+		_ = encoder.Encode(entry)
+	}
+
+	return func(next cmd.Handler[T]) cmd.Handler[T] {
+		return func(app app.V1[T], in *cmd.Input) error {
+			args, flags := redactInput(in)
+			command := strings.Join(in.Path, " ")
+
+			started := time.Now()
+			write(logEntry{Time: started, Event: logEventStart, Command: command, Args: args, Flags: flags})
+
+			err := next(app, in)
+
+			duration := time.Since(started).Milliseconds()
+			entry := logEntry{Time: time.Now(), Event: logEventEnd, Command: command, DurationMs: &duration}
+
+			if err != nil {
+				entry.Error = err.Error()
+			}
+
+			write(entry)
+
+			return err
+		}
+	}
+}
+
+// Audit publishes an AuditEvent through app.Msg() after each command, to topic
+// or, when empty, to the topic derived from the event type. A failed publish
+// is returned along with the command error. Commands whose Modules leave out
+// msg, like the reserved ones, are not audited so that they keep working
+// offline.
+func Audit[T any](topic string) cmd.Middleware[T] {
+	return func(next cmd.Handler[T]) cmd.Handler[T] {
+		return func(app app.V1[T], in *cmd.Input) error {
+			if !usesModule(in, msg.MODULE_NAME) {
+				return next(app, in)
+			}
+
+			args, flags := redactInput(in)
+			started := time.Now()
+
+			err := next(app, in)
+
+			event := &AuditEvent{
+				ID:         app.ID(),
+				Command:    strings.Join(in.Path, " "),
+				Args:       args,
+				Flags:      flags,
+				User:       currentUser(),
+				Host:       hostname(),
+				StartedAt:  started,
+				DurationMs: time.Since(started).Milliseconds(),
+			}
+
+			if err != nil {
+				event.Error = err.Error()
+			}
+
+			var publishErr error
+			if topic == "" {
+				publishErr = app.Msg().Publish(event.ID, event)
+			} else {
+				publishErr = app.Msg().PublishT(topic, event.ID, event)
+			}
+
+			if publishErr != nil {
+				return errors.Join(err, fmt.Errorf("%s: %w: %w", cmd.MODULE_NAME, errAudit, publishErr))
+			}
+
+			return err
+		}
+	}
+}
+
+// usesModule reports whether module is connected for the command of in.
+func usesModule(in *cmd.Input, module string) bool {
+	if in.Modules == nil {
+		return true
+	}
+
+	for _, name := range in.Modules {
+		if name == module {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactInput renders the args and flags of in for logs, masking flags that
+// hold secrets and "KEY=value" args whose key looks like a secret.
+func redactInput(in *cmd.Input) ([]string, map[string]string) {
+	args := make([]string, 0, len(in.Args))
+
+	for _, arg := range in.Args {
+		if key, _, found := strings.Cut(arg, "="); found && configV1.IsSecretName(strings.TrimLeft(key, "-")) {
+			arg = key + "=" + redactedValue
+		}
+
+		args = append(args, arg)
+	}
+
+	if in.Flags == nil {
+		return args, nil
+	}
+
+	fields, err := configV1.Values(in.Flags)
+	if err != nil {
+		return args, nil
+	}
+
+	flags := make(map[string]string, len(fields))
+	for _, field := range fields {
+		flags[flagName(field.Name)] = field.Value
+	}
+
+	return args, flags
+}
+
+func currentUser() string {
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+
+	return os.Getenv("USER")
+}
+
+func hostname() string {
+	name, _ := os.Hostname()
+
+	return name
+}
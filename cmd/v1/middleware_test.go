@@ -0,0 +1,196 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ampliway/way-lib-go/app"
+	"github.com/ampliway/way-lib-go/cmd"
+	"github.com/ampliway/way-lib-go/msg"
+	"github.com/stretchr/testify/assert"
+)
+
+type testSecretFlags struct {
+	Limit    int    `default:"10"`
+	Password string `required:"false"`
+}
+
+type recordingProducer struct {
+	topic      string
+	key        string
+	message    interface{}
+	publishErr error
+}
+
+func (p *recordingProducer) Publish(key string, m interface{}) error {
+	return p.PublishT("", key, m)
+}
+
+func (p *recordingProducer) PublishT(topicName, key string, m interface{}) error {
+	p.topic, p.key, p.message = topicName, key, m
+
+	return p.publishErr
+}
+
+func (p *recordingProducer) CreateTopicIfNotExist(string, int32, int16) error { return nil }
+func (p *recordingProducer) Shutdown()                                        {}
+
+type auditApp struct {
+	shutdownApp
+	producer *recordingProducer
+}
+
+func (a *auditApp) Msg() msg.ProducerV1 { return a.producer }
+
+func TestChain(t *testing.T) {
+	t.Parallel()
+
+	calls := []string{}
+	trace := func(name string) cmd.Middleware[testConfig] {
+		return func(next cmd.Handler[testConfig]) cmd.Handler[testConfig] {
+			return func(app app.V1[testConfig], in *cmd.Input) error {
+				calls = append(calls, name+">")
+				err := next(app, in)
+				calls = append(calls, "<"+name)
+
+				return err
+			}
+		}
+	}
+
+	adapter := New[testConfig]()
+	adapter.Use(trace("a"), trace("b"))
+	adapter.Use(Hooks[testConfig](
+		func(app app.V1[testConfig], in *cmd.Input) error {
+			calls = append(calls, "before")
+
+			return nil
+		},
+		func(app app.V1[testConfig], in *cmd.Input, err error) error {
+			calls = append(calls, "after")
+
+			return errors.Join(err, errors.New("after failed"))
+		},
+	))
+
+	handler := adapter.chain(func(app app.V1[testConfig], in *cmd.Input) error {
+		calls = append(calls, "execute")
+
+		return errors.New("execute failed")
+	})
+
+	err := handler(&shutdownApp{}, &cmd.Input{})
+	assert.Equal(t, "execute failed\nafter failed", err.Error())
+	assert.Equal(t, []string{"a>", "b>", "before", "execute", "after", "<b", "<a"}, calls)
+}
+
+func TestHooks_BeforeFails(t *testing.T) {
+	t.Parallel()
+
+	executed := false
+	handler := Hooks[testConfig](func(app app.V1[testConfig], in *cmd.Input) error {
+		return errors.New("not allowed")
+	}, nil)(func(app app.V1[testConfig], in *cmd.Input) error {
+		executed = true
+
+		return nil
+	})
+
+	assert.Equal(t, "not allowed", handler(&shutdownApp{}, &cmd.Input{}).Error())
+	assert.False(t, executed)
+}
+
+func TestTimingAndRecover(t *testing.T) {
+	t.Parallel()
+
+	var (
+		observed    time.Duration
+		observedErr error
+	)
+
+	handler := Timing[testConfig](func(in *cmd.Input, elapsed time.Duration, err error) {
+		observed, observedErr = elapsed, err
+	})(Recover[testConfig]()(func(app app.V1[testConfig], in *cmd.Input) error {
+		time.Sleep(5 * time.Millisecond)
+		panic("nil map")
+	}))
+
+	err := handler(&shutdownApp{}, &cmd.Input{})
+	assert.True(t, strings.HasPrefix(err.Error(), "cmd: command panicked: nil map\ngoroutine "))
+	assert.Equal(t, err, observedErr)
+	assert.GreaterOrEqual(t, observed, 5*time.Millisecond)
+}
+
+func TestLogging(t *testing.T) {
+	t.Parallel()
+
+	output := &bytes.Buffer{}
+	handler := Logging[testConfig](output)(func(app app.V1[testConfig], in *cmd.Input) error {
+		return errors.New("boom")
+	})
+
+	in := &cmd.Input{
+		Path:  []string{"db", "migrate"},
+		Args:  []string{"users", "API_KEY=abc", "--db-password=x"},
+		Flags: &testSecretFlags{Limit: 2, Password: "p@ss"},
+	}
+	assert.Equal(t, "boom", handler(&shutdownApp{}, in).Error())
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	assert.Len(t, lines, 2)
+
+	start := logEntry{}
+	assert.Equal(t, nil, json.Unmarshal([]byte(lines[0]), &start))
+	assert.Equal(t, logEventStart, start.Event)
+	assert.Equal(t, "db migrate", start.Command)
+	assert.Equal(t, []string{"users", "API_KEY=******", "--db-password=******"}, start.Args)
+	assert.Equal(t, map[string]string{"--limit": "2", "--password": "******"}, start.Flags)
+
+	end := logEntry{}
+	assert.Equal(t, nil, json.Unmarshal([]byte(lines[1]), &end))
+	assert.Equal(t, logEventEnd, end.Event)
+	assert.Equal(t, "boom", end.Error)
+	assert.NotNil(t, end.DurationMs)
+}
+
+func TestAudit(t *testing.T) {
+	t.Parallel()
+
+	fakeApp := &auditApp{producer: &recordingProducer{}}
+	in := &cmd.Input{Path: []string{"users", "delete"}, Args: []string{"42"}}
+
+	handler := Audit[testConfig]("ops.audit")(func(app app.V1[testConfig], in *cmd.Input) error {
+		return nil
+	})
+	assert.Equal(t, nil, handler(fakeApp, in))
+
+	event := fakeApp.producer.message.(*AuditEvent)
+	assert.Equal(t, "ops.audit", fakeApp.producer.topic)
+	assert.Equal(t, "id", fakeApp.producer.key)
+	assert.Equal(t, "users delete", event.Command)
+	assert.Equal(t, []string{"42"}, event.Args)
+	assert.Equal(t, "", event.Error)
+	assert.False(t, event.StartedAt.IsZero())
+
+	fakeApp.producer.publishErr = errors.New("broker down")
+	handler = Audit[testConfig]("")(func(app app.V1[testConfig], in *cmd.Input) error {
+		return errors.New("boom")
+	})
+
+	err := handler(fakeApp, in)
+	assert.Equal(t, "boom\ncmd: audit publish failed: broker down", err.Error())
+	assert.Equal(t, "", fakeApp.producer.topic)
+	assert.Equal(t, "boom", fakeApp.producer.message.(*AuditEvent).Error)
+
+	fakeApp.producer = &recordingProducer{publishErr: errors.New("broker down")}
+	offline := &cmd.Input{Path: []string{"help"}, Modules: []string{}}
+
+	assert.Equal(t, nil, Audit[testConfig]("")(func(app app.V1[testConfig], in *cmd.Input) error {
+		return nil
+	})(fakeApp, offline))
+	assert.Nil(t, fakeApp.producer.message)
+}
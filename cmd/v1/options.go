@@ -0,0 +1,45 @@
+package v1
+
+import "time"
+
+const (
+	defaultGracePeriod       = 10 * time.Second
+	defaultRestartBackoffMin = 100 * time.Millisecond
+	defaultRestartBackoffMax = 30 * time.Second
+)
+
+type Option func(*settings)
+
+type settings struct {
+	gracePeriod       time.Duration
+	restartBackoffMin time.Duration
+	restartBackoffMax time.Duration
+}
+
+func defaultSettings() settings {
+	return settings{
+		gracePeriod:       defaultGracePeriod,
+		restartBackoffMin: defaultRestartBackoffMin,
+		restartBackoffMax: defaultRestartBackoffMax,
+	}
+}
+
+// WithGracePeriod sets how long a command has to return after SIGINT or
+// SIGTERM, and then how long the app modules have to shut down.
+func WithGracePeriod(gracePeriod time.Duration) Option {
+	return func(s *settings) {
+		if gracePeriod > 0 {
+			s.gracePeriod = gracePeriod
+		}
+	}
+}
+
+// WithRestartBackoff bounds the wait before a failed worker is restarted.
+func WithRestartBackoff(min, max time.Duration) Option {
+	return func(s *settings) {
+		if min > 0 && max >= min {
+			s.restartBackoffMin = min
+			s.restartBackoffMax = max
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ampliway/way-lib-go/cmd"
+	configV1 "github.com/ampliway/way-lib-go/config/v1"
+	"github.com/iancoleman/strcase"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	_ cmd.Output = (*jsonOutput)(nil)
+	_ cmd.Output = (*yamlOutput)(nil)
+	_ cmd.Output = (*tableOutput)(nil)
+
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+func newOutput(format string, w io.Writer) (cmd.Output, error) {
+	switch format {
+	case cmd.OutputTable:
+		return &tableOutput{w: w}, nil
+	case cmd.OutputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return &jsonOutput{encoder: encoder}, nil
+	case cmd.OutputYAML:
+		return &yamlOutput{w: w}, nil
+	default:
+		return nil, fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errUnknownFormat, format)
+	}
+}
+
+type jsonOutput struct {
+	encoder *json.Encoder
+}
+
+func (o *jsonOutput) Write(value any) error {
+	return o.encoder.Encode(value)
+}
+
+// yamlOutput goes through JSON, so that both formats name the fields after
+// the json tags; each write is a YAML document.
+type yamlOutput struct {
+	w       io.Writer
+	written bool
+}
+
+func (o *yamlOutput) Write(value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	node := &yaml.Node{}
+	if err := yaml.Unmarshal(data, node); err != nil {
+		return err
+	}
+
+	resetStyle(node)
+
+	if o.written {
+		if _, err := io.WriteString(o.w, "---\n"); err != nil {
+			return err
+		}
+	}
+
+	o.written = true
+
+	encoder := yaml.NewEncoder(o.w)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(node); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// resetStyle drops the JSON flow style and quotes so the encoder picks the
+// block style, quoting only the strings that need it.
+func resetStyle(node *yaml.Node) {
+	node.Style = 0
+
+	for _, child := range node.Content {
+		resetStyle(child)
+	}
+}
+
+// tableOutput prints a slice of structs or a struct as a table, a map as
+// KEY VALUE rows and anything else, errors and Stringers included, on its own
+// line.
+type tableOutput struct {
+	w io.Writer
+}
+
+func (o *tableOutput) Write(value any) error {
+	switch value.(type) {
+	case error, fmt.Stringer:
+		_, err := fmt.Fprintln(o.w, configV1.FormatValue(reflect.ValueOf(value)))
+
+		return err
+	}
+
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+
+		v = v.Elem()
+	}
+
+	switch {
+	case !v.IsValid():
+		return nil
+	case v.Kind() == reflect.Struct && v.Type() != timeType:
+		return o.writeRows(v.Type(), []reflect.Value{v})
+	case (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) && isStructType(v.Type().Elem()):
+		rows := make([]reflect.Value, 0, v.Len())
+
+		for i := 0; i < v.Len(); i++ {
+			row := v.Index(i)
+			for row.Kind() == reflect.Ptr {
+				row = row.Elem()
+			}
+
+			rows = append(rows, row)
+		}
+
+		return o.writeRows(derefType(v.Type().Elem()), rows)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8:
+		for i := 0; i < v.Len(); i++ {
+			if _, err := fmt.Fprintln(o.w, configV1.FormatValue(v.Index(i))); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case v.Kind() == reflect.Map:
+		return o.writeMap(v)
+	default:
+		_, err := fmt.Fprintln(o.w, configV1.FormatValue(v))
+
+		return err
+	}
+}
+
+func (o *tableOutput) writeRows(t reflect.Type, rows []reflect.Value) error {
+	columns := tableColumns(t)
+	tw := tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)
+
+	headers := make([]string, 0, len(columns))
+	for _, column := range columns {
+		headers = append(headers, column.header)
+	}
+
+	fmt.Fprintln(tw, strings.Join(headers, "\t"))
+
+	for _, row := range rows {
+		if !row.IsValid() {
+			continue
+		}
+
+		cells := make([]string, 0, len(columns))
+		for _, column := range columns {
+			// a field promoted through a nil embedded pointer is left empty
+			cell, err := row.FieldByIndexErr(column.index)
+			if err != nil {
+				cells = append(cells, "")
+
+				continue
+			}
+
+			cells = append(cells, configV1.FormatValue(cell))
+		}
+
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+
+	return tw.Flush()
+}
+
+func (o *tableOutput) writeMap(v reflect.Value) error {
+	rows := make([][2]string, 0, v.Len())
+	for _, key := range v.MapKeys() {
+		rows = append(rows, [2]string{configV1.FormatValue(key), configV1.FormatValue(v.MapIndex(key))})
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i][0] < rows[j][0]
+	})
+
+	tw := tabwriter.NewWriter(o.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KEY\tVALUE")
+
+	for _, row := range rows {
+		fmt.Fprintf(tw, "%s\t%s\n", row[0], row[1])
+	}
+
+	return tw.Flush()
+}
+
+type tableColumn struct {
+	header string
+	index  []int
+}
+
+// tableColumns lists the exported fields of t, skipping json:"-", with
+// headers like "DURATION MS" from the json name or the field name.
+func tableColumns(t reflect.Type) []tableColumn {
+	result := []tableColumn{}
+
+	for _, field := range reflect.VisibleFields(t) {
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+
+		name := field.Name
+
+		tag, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if tag == "-" {
+			continue
+		}
+
+		if tag != "" {
+			name = tag
+		}
+
+		result = append(result, tableColumn{
+			header: strcase.ToScreamingDelimited(name, ' ', "", true),
+			index:  field.Index,
+		})
+	}
+
+	return result
+}
+
+func derefType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return t
+}
+
+func isStructType(t reflect.Type) bool {
+	t = derefType(t)
+
+	return t.Kind() == reflect.Struct && t != timeType
+}
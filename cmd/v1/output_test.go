@@ -0,0 +1,224 @@
+package v1
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ampliway/way-lib-go/app"
+	appV1 "github.com/ampliway/way-lib-go/app/v1"
+	"github.com/ampliway/way-lib-go/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+type testRun struct {
+	Name       string        `json:"name"`
+	DurationMs int64         `json:"durationMs"`
+	Tags       []string      `json:"tags,omitempty"`
+	Wait       time.Duration `json:"wait"`
+	StartedAt  time.Time     `json:"startedAt"`
+	Err        error         `json:"-"`
+	Enabled    string
+	internal   bool
+}
+
+type testBase struct {
+	ID string `json:"id"`
+}
+
+type testEmbedded struct {
+	Name string `json:"name"`
+	*testBase
+}
+
+func TestOutputArgs(t *testing.T) {
+	t.Parallel()
+
+	tableTest := []struct {
+		Args           []string
+		ExpectedFormat string
+		ExpectedArgs   []string
+		ExpectedErr    string
+	}{
+		{Args: []string{"commands"}, ExpectedFormat: cmd.OutputTable, ExpectedArgs: []string{"commands"}},
+		{Args: []string{"--output=json", "commands"}, ExpectedFormat: cmd.OutputJSON, ExpectedArgs: []string{"commands"}},
+		{Args: []string{"topics", "--output", "yaml", "list"}, ExpectedFormat: cmd.OutputYAML, ExpectedArgs: []string{"topics", "list"}},
+		{Args: []string{"echo", "--", "--output=json"}, ExpectedFormat: cmd.OutputTable, ExpectedArgs: []string{"echo", "--", "--output=json"}},
+		{Args: []string{"commands", "--output"}, ExpectedErr: "cmd: flag needs a value: --output"},
+	}
+
+	for _, rowTest := range tableTest {
+		format, args, err := outputArgs(rowTest.Args)
+		if rowTest.ExpectedErr != "" {
+			assert.Equal(t, rowTest.ExpectedErr, err.Error())
+
+			continue
+		}
+
+		assert.Equal(t, nil, err)
+		assert.Equal(t, rowTest.ExpectedFormat, format)
+		assert.Equal(t, rowTest.ExpectedArgs, args)
+	}
+}
+
+func TestOutput(t *testing.T) {
+	t.Parallel()
+
+	startedAt := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+	runs := []*testRun{
+		{Name: "cleanup", DurationMs: 12, Tags: []string{"a", "b"}, Wait: time.Second, StartedAt: startedAt, Err: errors.New("x"), Enabled: "true"},
+		nil,
+		{Name: "backup"},
+	}
+
+	tableTest := []struct {
+		Scenario string
+		Format   string
+		Values   []any
+		Expected string
+	}{
+		{
+			Scenario: "table_rows",
+			Format:   cmd.OutputTable,
+			Values:   []any{runs},
+			Expected: "" +
+				"NAME     DURATION MS  TAGS  WAIT  STARTED AT            ENABLED\n" +
+				"cleanup  12           a,b   1s    2023-08-01T10:00:00Z  true\n" +
+				"backup   0                  0s                          \n",
+		},
+		{
+			Scenario: "table_nil_embedded",
+			Format:   cmd.OutputTable,
+			Values:   []any{[]testEmbedded{{Name: "a"}, {Name: "b", testBase: &testBase{ID: "1"}}}},
+			Expected: "" +
+				"NAME  ID\n" +
+				"a     \n" +
+				"b     1\n",
+		},
+		{
+			Scenario: "table_struct",
+			Format:   cmd.OutputTable,
+			Values:   []any{commandRow{Command: "db migrate", Description: "Run migrations"}},
+			Expected: "" +
+				"COMMAND     DESCRIPTION\n" +
+				"db migrate  Run migrations\n",
+		},
+		{
+			Scenario: "table_map_and_scalars",
+			Format:   cmd.OutputTable,
+			Values:   []any{map[string]int{"b": 2, "a": 1}, []string{"x", "y"}, "done", nil, errors.New("partial")},
+			Expected: "" +
+				"KEY  VALUE\n" +
+				"a    1\n" +
+				"b    2\n" +
+				"x\n" +
+				"y\n" +
+				"done\n" +
+				"partial\n",
+		},
+		{
+			Scenario: "json",
+			Format:   cmd.OutputJSON,
+			Values:   []any{[]commandRow{{Command: "db", Description: "Database"}}, 3},
+			Expected: "" +
+				"[\n" +
+				"  {\n" +
+				"    \"command\": \"db\",\n" +
+				"    \"description\": \"Database\"\n" +
+				"  }\n" +
+				"]\n" +
+				"3\n",
+		},
+		{
+			Scenario: "yaml",
+			Format:   cmd.OutputYAML,
+			Values:   []any{[]*testRun{runs[2]}, map[string]string{"flag": "true", "count": "10"}},
+			Expected: "" +
+				"- name: backup\n" +
+				"  durationMs: 0\n" +
+				"  wait: 0\n" +
+				"  startedAt: \"0001-01-01T00:00:00Z\"\n" +
+				"  Enabled: \"\"\n" +
+				"---\n" +
+				"count: \"10\"\n" +
+				"flag: \"true\"\n",
+		},
+	}
+
+	for _, rowTest := range tableTest {
+		rowTest := rowTest
+
+		t.Run(rowTest.Scenario, func(t *testing.T) {
+			t.Parallel()
+
+			buffer := &bytes.Buffer{}
+
+			output, err := newOutput(rowTest.Format, buffer)
+			assert.Equal(t, nil, err)
+
+			for _, value := range rowTest.Values {
+				assert.Equal(t, nil, output.Write(value))
+			}
+
+			assert.Equal(t, rowTest.Expected, buffer.String())
+		})
+	}
+
+	_, err := newOutput("xml", &bytes.Buffer{})
+	assert.Equal(t, "cmd: unknown output format: xml", err.Error())
+}
+
+func TestRun_Output(t *testing.T) {
+	t.Parallel()
+
+	adapter := New[testConfig]()
+	adapter.configs = adapter.configs[:0]
+
+	assert.Equal(t, nil, adapter.Add(&cmd.Config[testConfig]{
+		Name:        "runs",
+		Description: "List runs",
+		Modules:     []string{},
+		Execute: func(app app.V1[testConfig], in *cmd.Input) error {
+			return in.Output.Write([]commandRow{{Command: "cleanup", Description: "ok"}})
+		},
+	}))
+
+	type outputFlags struct {
+		Output string
+	}
+
+	err := adapter.Add(&cmd.Config[testConfig]{
+		Name:        "export",
+		Description: "Export",
+		Flags:       &outputFlags{},
+		Execute:     adapter.configs[0].Execute,
+	})
+	assert.Equal(t, "cmd: config flag is reserved: --output", err.Error())
+
+	tableTest := []struct {
+		Arguments      string
+		ExpectedStdout string
+		ExpectedErr    string
+	}{
+		{Arguments: "runs", ExpectedStdout: "COMMAND  DESCRIPTION\ncleanup  ok\n"},
+		{Arguments: "--output yaml runs", ExpectedStdout: "- command: cleanup\n  description: ok\n"},
+		{Arguments: "runs --output=json", ExpectedStdout: "[\n  {\n    \"command\": \"cleanup\",\n    \"description\": \"ok\"\n  }\n]\n"},
+		{Arguments: "runs --output=csv", ExpectedErr: "cmd: unknown output format: csv"},
+	}
+
+	for _, rowTest := range tableTest {
+		stdout := &bytes.Buffer{}
+
+		err := adapter.RunSession(&Session[testConfig]{App: appV1.NewMock[testConfig](nil), Stdout: stdout}, rowTest.Arguments)
+		if rowTest.ExpectedErr != "" {
+			assert.Equal(t, rowTest.ExpectedErr, err.Error())
+			assert.Equal(t, cmd.ExitUsage, cmd.ExitCode(err))
+
+			continue
+		}
+
+		assert.Equal(t, nil, err)
+		assert.Equal(t, rowTest.ExpectedStdout, stdout.String())
+	}
+}
@@ -0,0 +1,354 @@
+package v1
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ampliway/way-lib-go/app"
+	"github.com/ampliway/way-lib-go/cache"
+	"github.com/ampliway/way-lib-go/cmd"
+)
+
+const (
+	scheduleLockPrefix   = "schedule:"
+	scheduleHistoryLimit = 100
+)
+
+var _ cmd.Worker = (*Scheduler[any])(nil)
+
+type scheduleFlags struct {
+	Jitter time.Duration `default:"0s" desc:"Max random delay added before each run"`
+	Lock   bool          `required:"false" desc:"Take a lock in the cache so a single replica runs each job"`
+}
+
+type SchedulerOption func(*schedulerSettings)
+
+type schedulerSettings struct {
+	jitter time.Duration
+	lock   bool
+	stdout io.Writer
+	stderr io.Writer
+	log    io.Writer
+}
+
+// WithJobJitter delays each run by a random duration up to jitter, spreading
+// the jobs of several processes due at the same time.
+func WithJobJitter(jitter time.Duration) SchedulerOption {
+	return func(s *schedulerSettings) {
+		s.jitter = jitter
+	}
+}
+
+// WithJobLock makes each run take a lock in the app cache first, so that only
+// one of the replicas sharing the cache runs it. The cache must implement
+// cache.Locker.
+func WithJobLock() SchedulerOption {
+	return func(s *schedulerSettings) {
+		s.lock = true
+	}
+}
+
+// WithJobOutput sets where the jobs print, os.Stdout and os.Stderr by default.
+func WithJobOutput(stdout, stderr io.Writer) SchedulerOption {
+	return func(s *schedulerSettings) {
+		s.stdout = stdout
+		s.stderr = stderr
+	}
+}
+
+// WithJobLog sets where a line is written for every run as it ends, and for
+// every run skipped, os.Stderr by default.
+func WithJobLog(w io.Writer) SchedulerOption {
+	return func(s *schedulerSettings) {
+		s.log = w
+	}
+}
+
+// Scheduler runs commands of a Cmd on cron expressions, all over the same
+// app. A run still going when the job is due again skips that run. It is a
+// cmd.Worker, to be returned by Config.Workers; the "schedule" reserved
+// command runs one, whose runs Cmd.ScheduleHistory returns.
+type Scheduler[T any] struct {
+	cmd      *Cmd[T]
+	app      app.V1[T]
+	settings schedulerSettings
+	jobs     []*scheduledJob
+	now      func() time.Time
+	after    func(d time.Duration) <-chan time.Time
+	running  sync.WaitGroup
+
+	mux     sync.Mutex
+	history []cmd.JobRun
+}
+
+type scheduledJob struct {
+	expr     string
+	command  string
+	args     []string
+	schedule *cronSchedule
+	next     time.Time
+	running  atomic.Bool
+}
+
+func NewScheduler[T any](c *Cmd[T], app app.V1[T], options ...SchedulerOption) *Scheduler[T] {
+	settings := schedulerSettings{
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+		log:    os.Stderr,
+	}
+
+	for _, option := range options {
+		option(&settings)
+	}
+
+	return &Scheduler[T]{
+		cmd:      c,
+		app:      app,
+		settings: settings,
+		jobs:     []*scheduledJob{},
+		now:      time.Now,
+		after:    time.After,
+		history:  []cmd.JobRun{},
+	}
+}
+
+// Add schedules the command line on expr, a five field cron expression or a
+// descriptor like "@hourly". The command is looked up now, so mistakes surface
+// before the scheduler starts.
+func (s *Scheduler[T]) Add(expr, command string) error {
+	schedule, err := parseCron(expr)
+	if err != nil {
+		return err
+	}
+
+	words, err := shellSplit(command)
+	if err != nil {
+		return err
+	}
+
+	_, args, err := outputArgs(words)
+	if err != nil {
+		return err
+	}
+
+	args, _, err = commandArgs(args)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("%s: %w", cmd.MODULE_NAME, errEmptyArguments)
+	}
+
+	match, path, _ := s.cmd.findConfig(args...)
+	if match == nil {
+		return unknownCommand(s.cmd.configs, args[0])
+	}
+
+	if match.Execute == nil || match == s.cmd.shellConfig {
+		return fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errScheduleCommand, strings.Join(path, " "))
+	}
+
+	s.jobs = append(s.jobs, &scheduledJob{
+		expr:     expr,
+		command:  command,
+		args:     words,
+		schedule: schedule,
+	})
+
+	return nil
+}
+
+// Start runs the jobs as they come due until ctx is done, then waits for the
+// runs in progress, whose context is cancelled too.
+func (s *Scheduler[T]) Start(ctx context.Context) error {
+	if len(s.jobs) == 0 {
+		return fmt.Errorf("%s: %w", cmd.MODULE_NAME, errScheduleEmpty)
+	}
+
+	defer s.running.Wait()
+
+	now := s.now()
+	for _, job := range s.jobs {
+		job.next = job.schedule.next(now)
+	}
+
+	for {
+		due := time.Time{}
+
+		for _, job := range s.jobs {
+			if !job.next.IsZero() && (due.IsZero() || job.next.Before(due)) {
+				due = job.next
+			}
+		}
+
+		if due.IsZero() {
+			<-ctx.Done()
+
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-s.after(due.Sub(s.now())):
+		}
+
+		for _, job := range s.jobs {
+			if !job.next.Equal(due) {
+				continue
+			}
+
+			s.dispatch(ctx, job, due)
+
+			// A clock jump or a suspended process skips the runs missed.
+			from := s.now()
+			if from.Before(due) {
+				from = due
+			}
+
+			job.next = job.schedule.next(from)
+		}
+	}
+}
+
+func (s *Scheduler[T]) Stop(ctx context.Context) error {
+	return nil
+}
+
+// History returns the last runs, oldest first; WithJobLog reports them as
+// they end.
+func (s *Scheduler[T]) History() []cmd.JobRun {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	return append([]cmd.JobRun{}, s.history...)
+}
+
+// ScheduleHistory returns the last runs of the "schedule" reserved command
+// started by c in this process, oldest first, for a status endpoint or a
+// worker of the same process to report; nil when it was never started.
+func (c *Cmd[T]) ScheduleHistory() []cmd.JobRun {
+	scheduler := c.scheduler.Load()
+	if scheduler == nil {
+		return nil
+	}
+
+	return scheduler.History()
+}
+
+func (s *Scheduler[T]) dispatch(ctx context.Context, job *scheduledJob, due time.Time) {
+	if !job.running.CompareAndSwap(false, true) {
+		s.logf("%s: job %q due at %s skipped, previous run still running", cmd.MODULE_NAME, job.command, due.Format(time.RFC3339))
+
+		return
+	}
+
+	s.running.Add(1)
+
+	go func() {
+		defer s.running.Done()
+		defer job.running.Store(false)
+
+		if s.settings.jitter > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.after(time.Duration(rand.Int63n(int64(s.settings.jitter)))):
+			}
+		}
+
+		if s.settings.lock {
+			locked, err := s.lock(job, due)
+			if err != nil {
+				now := s.now()
+				s.record(cmd.JobRun{Command: job.command, Schedule: job.expr, ScheduledAt: due, StartedAt: now, EndedAt: now, Err: err})
+
+				return
+			}
+
+			if !locked {
+				s.logf("%s: job %q due at %s skipped, run by another replica", cmd.MODULE_NAME, job.command, due.Format(time.RFC3339))
+
+				return
+			}
+		}
+
+		s.run(ctx, job, due)
+	}()
+}
+
+// lock elects one replica for the run due at due: the key is unique to the
+// run and expires when the job is due again.
+func (s *Scheduler[T]) lock(job *scheduledJob, due time.Time) (bool, error) {
+	key := scheduleLockPrefix + job.command + ":" + due.UTC().Format(time.RFC3339)
+
+	expiration := time.Minute
+	if next := job.schedule.next(due); !next.IsZero() {
+		expiration = next.Sub(due)
+	}
+
+	locker, ok := s.app.Cache().(cache.Locker)
+	if !ok {
+		return false, fmt.Errorf("%s: %w: %w", cmd.MODULE_NAME, errScheduleLock, errScheduleLocker)
+	}
+
+	locked, err := locker.SetNX(key, s.app.ID(), expiration)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w: %w", cmd.MODULE_NAME, errScheduleLock, err)
+	}
+
+	return locked, nil
+}
+
+func (s *Scheduler[T]) run(ctx context.Context, job *scheduledJob, due time.Time) {
+	run := cmd.JobRun{
+		Command:     job.command,
+		Schedule:    job.expr,
+		ScheduledAt: due,
+		StartedAt:   s.now(),
+	}
+
+	run.Err = s.cmd.run(&Session[T]{
+		App:    s.app,
+		Stdin:  strings.NewReader(""),
+		Stdout: s.settings.stdout,
+		Stderr: s.settings.stderr,
+		ctx:    ctx,
+	}, append([]string{}, job.args...))
+	run.EndedAt = s.now()
+
+	s.record(run)
+}
+
+// record keeps run in the history and logs it.
+func (s *Scheduler[T]) record(run cmd.JobRun) {
+	status := "ok"
+	if run.Err != nil {
+		status = "failed: " + run.Err.Error()
+	}
+
+	s.logf("%s: job %q due at %s ran in %s: %s", cmd.MODULE_NAME, run.Command, run.ScheduledAt.Format(time.RFC3339), run.EndedAt.Sub(run.StartedAt), status)
+
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	s.history = append(s.history, run)
+	if len(s.history) > scheduleHistoryLimit {
+		s.history = s.history[len(s.history)-scheduleHistoryLimit:]
+	}
+}
+
+func (s *Scheduler[T]) logf(format string, args ...any) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+
+	fmt.Fprintf(s.settings.log, format+"\n", args...)
+}
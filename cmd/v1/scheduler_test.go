@@ -0,0 +1,294 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ampliway/way-lib-go/app"
+	appV1 "github.com/ampliway/way-lib-go/app/v1"
+	"github.com/ampliway/way-lib-go/cache"
+	cacheV1 "github.com/ampliway/way-lib-go/cache/v1"
+	"github.com/ampliway/way-lib-go/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+type jobFlags struct {
+	Days int `required:"false" default:"7"`
+}
+
+func newSchedulerAdapter(t *testing.T, execute func(app app.V1[testConfig], in *cmd.Input) error) *Cmd[testConfig] {
+	t.Helper()
+
+	adapter := New[testConfig]()
+	assert.Equal(t, nil, adapter.Add(&cmd.Config[testConfig]{
+		Name:        "cleanup",
+		Description: "Remove old files",
+		Flags:       &jobFlags{},
+		Modules:     []string{},
+		Execute:     execute,
+	}))
+	assert.Equal(t, nil, adapter.Add(&cmd.Config[testConfig]{
+		Name:        "db",
+		Description: "Database commands",
+		Commands: []*cmd.Config[testConfig]{
+			{Name: "vacuum", Description: "Vacuum", Execute: execute},
+		},
+	}))
+
+	return adapter
+}
+
+func TestScheduler_Add(t *testing.T) {
+	t.Parallel()
+
+	adapter := newSchedulerAdapter(t, func(app app.V1[testConfig], in *cmd.Input) error {
+		return nil
+	})
+	scheduler := NewScheduler[testConfig](adapter, appV1.NewMock[testConfig](nil))
+
+	tableTest := []struct {
+		Expr        string
+		Command     string
+		ExpectedErr string
+	}{
+		{Expr: "0 * * * *", Command: "cleanup --days 1"},
+		{Expr: "@daily", Command: "db vacuum"},
+		{Expr: "0 * * *", Command: "cleanup", ExpectedErr: `cmd: invalid cron expression: "0 * * *": expected 5 fields`},
+		{Expr: "@daily", Command: "cleanp", ExpectedErr: `cmd: unknown command: cleanp, did you mean "cleanup"?`},
+		{Expr: "@daily", Command: "db", ExpectedErr: "cmd: only commands with execute can be scheduled: db"},
+		{Expr: "@daily", Command: "shell", ExpectedErr: "cmd: only commands with execute can be scheduled: shell"},
+		{Expr: "@daily", Command: " ", ExpectedErr: "cmd: with empty arguments"},
+	}
+
+	for _, rowTest := range tableTest {
+		err := scheduler.Add(rowTest.Expr, rowTest.Command)
+		if rowTest.ExpectedErr == "" {
+			assert.Equal(t, nil, err)
+
+			continue
+		}
+
+		assert.Equal(t, rowTest.ExpectedErr, err.Error())
+	}
+
+	assert.Len(t, scheduler.jobs, 2)
+	assert.Equal(t, "cmd: no job scheduled", NewScheduler[testConfig](adapter, nil).Start(context.Background()).Error())
+}
+
+func TestScheduler_Start(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var received *cmd.Input
+
+	adapter := newSchedulerAdapter(t, func(app app.V1[testConfig], in *cmd.Input) error {
+		received = in
+		cancel()
+
+		<-in.Context().Done()
+
+		return errors.New("stopped")
+	})
+
+	now := time.Date(2023, 8, 1, 10, 17, 42, 0, time.UTC)
+	fired := false
+	log := &bytes.Buffer{}
+
+	scheduler := NewScheduler[testConfig](adapter, appV1.NewMock[testConfig](nil), WithJobLog(log))
+	scheduler.now = func() time.Time { return now }
+	scheduler.after = func(d time.Duration) <-chan time.Time {
+		if fired {
+			return nil
+		}
+
+		fired = true
+		now = now.Add(d)
+
+		result := make(chan time.Time, 1)
+		result <- now
+
+		return result
+	}
+
+	assert.Equal(t, nil, scheduler.Add("*/5 * * * *", "cleanup --days 1 old"))
+	assert.Equal(t, nil, scheduler.Add("@daily", "db vacuum"))
+	assert.Equal(t, nil, scheduler.Start(ctx))
+
+	assert.Equal(t, []string{"cleanup"}, received.Path)
+	assert.Equal(t, []string{"old"}, received.Args)
+	assert.Equal(t, &jobFlags{Days: 1}, received.Flags)
+
+	due := time.Date(2023, 8, 1, 10, 20, 0, 0, time.UTC)
+	history := scheduler.History()
+	assert.Len(t, history, 1)
+	assert.Equal(t, "cleanup --days 1 old", history[0].Command)
+	assert.Equal(t, "*/5 * * * *", history[0].Schedule)
+	assert.Equal(t, due, history[0].ScheduledAt)
+	assert.Equal(t, due, history[0].StartedAt)
+	assert.Equal(t, due, history[0].EndedAt)
+	assert.Equal(t, "cmd: execution failed: cleanup: stopped", history[0].Err.Error())
+	assert.Equal(t, "cmd: job \"cleanup --days 1 old\" due at 2023-08-01T10:20:00Z ran in 0s: failed: cmd: execution failed: cleanup: stopped\n", log.String())
+}
+
+func TestScheduler_Overlap(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	started := make(chan struct{}, 2)
+
+	adapter := newSchedulerAdapter(t, func(app app.V1[testConfig], in *cmd.Input) error {
+		started <- struct{}{}
+		<-release
+
+		return nil
+	})
+
+	log := &bytes.Buffer{}
+
+	scheduler := NewScheduler[testConfig](adapter, appV1.NewMock[testConfig](nil), WithJobLog(log))
+	assert.Equal(t, nil, scheduler.Add("* * * * *", "cleanup"))
+
+	due := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+	job := scheduler.jobs[0]
+
+	scheduler.dispatch(context.Background(), job, due)
+	<-started
+	scheduler.dispatch(context.Background(), job, due.Add(time.Minute))
+	close(release)
+	scheduler.running.Wait()
+
+	history := scheduler.History()
+	assert.Len(t, history, 1)
+	assert.Equal(t, due, history[0].ScheduledAt)
+	assert.Contains(t, log.String(), "cmd: job \"cleanup\" due at 2023-08-01T10:01:00Z skipped, previous run still running\n")
+}
+
+func TestScheduler_LockAndJitter(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mux  sync.Mutex
+		runs int
+	)
+
+	adapter := newSchedulerAdapter(t, func(app app.V1[testConfig], in *cmd.Input) error {
+		mux.Lock()
+		defer mux.Unlock()
+
+		runs++
+
+		return nil
+	})
+
+	cache := cacheV1.NewMock()
+	due := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+	delays := make(chan time.Duration, 2)
+	log := &bytes.Buffer{}
+
+	for i := 0; i < 2; i++ {
+		replica := appV1.NewMock[testConfig](nil)
+		replica.SetCache(cache)
+
+		scheduler := NewScheduler[testConfig](adapter, replica, WithJobLock(), WithJobJitter(time.Second), WithJobLog(log))
+		scheduler.after = func(d time.Duration) <-chan time.Time {
+			delays <- d
+
+			result := make(chan time.Time, 1)
+			result <- due
+
+			return result
+		}
+
+		assert.Equal(t, nil, scheduler.Add("0 * * * *", "cleanup"))
+		scheduler.dispatch(context.Background(), scheduler.jobs[0], due)
+		scheduler.running.Wait()
+	}
+
+	assert.Equal(t, 1, runs)
+
+	for i := 0; i < 2; i++ {
+		delay := <-delays
+		assert.True(t, delay >= 0 && delay < time.Second)
+	}
+
+	lock, _ := cache.Get("schedule:cleanup:2023-08-01T10:00:00Z")
+	assert.NotEqual(t, "", lock)
+	assert.Contains(t, log.String(), "cmd: job \"cleanup\" due at 2023-08-01T10:00:00Z skipped, run by another replica\n")
+}
+
+func TestScheduler_LockWithoutLocker(t *testing.T) {
+	t.Parallel()
+
+	adapter := newSchedulerAdapter(t, func(app app.V1[testConfig], in *cmd.Input) error {
+		return nil
+	})
+
+	replica := appV1.NewMock[testConfig](nil)
+	replica.SetCache(struct{ cache.V1 }{cacheV1.NewMock()})
+
+	scheduler := NewScheduler[testConfig](adapter, replica, WithJobLock(), WithJobLog(&bytes.Buffer{}))
+	assert.Equal(t, nil, scheduler.Add("0 * * * *", "cleanup"))
+	scheduler.dispatch(context.Background(), scheduler.jobs[0], time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC))
+	scheduler.running.Wait()
+
+	history := scheduler.History()
+	assert.Len(t, history, 1)
+	assert.Equal(t, "cmd: schedule lock failed: cache does not implement cache.Locker", history[0].Err.Error())
+}
+
+func TestRun_Schedule(t *testing.T) {
+	t.Parallel()
+
+	adapter := newSchedulerAdapter(t, func(app app.V1[testConfig], in *cmd.Input) error {
+		return nil
+	})
+
+	tableTest := []struct {
+		Arguments    string
+		ExpectedErr  string
+		ExpectedCode int
+	}{
+		{Arguments: "schedule", ExpectedErr: "cmd: execution failed: schedule: cmd: usage: schedule <cron> <command> [<cron> <command>]...", ExpectedCode: cmd.ExitUsage},
+		{Arguments: `schedule "0 * * * *"`, ExpectedErr: "cmd: execution failed: schedule: cmd: usage: schedule <cron> <command> [<cron> <command>]...", ExpectedCode: cmd.ExitUsage},
+		{Arguments: `schedule "0 * *" cleanup`, ExpectedErr: `cmd: execution failed: schedule: cmd: invalid cron expression: "0 * *": expected 5 fields`, ExpectedCode: cmd.ExitUsage},
+		{Arguments: `schedule --jitter 1s "0 * * * *" cleanup @daily nothing`, ExpectedErr: "cmd: execution failed: schedule: cmd: unknown command: nothing", ExpectedCode: cmd.ExitUsage},
+	}
+
+	for _, rowTest := range tableTest {
+		err := adapter.RunSession(&Session[testConfig]{App: appV1.NewMock[testConfig](nil)}, rowTest.Arguments)
+		assert.Equal(t, rowTest.ExpectedErr, err.Error())
+		assert.Equal(t, rowTest.ExpectedCode, cmd.ExitCode(err))
+	}
+}
+
+func TestCmd_ScheduleHistory(t *testing.T) {
+	t.Parallel()
+
+	adapter := newSchedulerAdapter(t, func(app app.V1[testConfig], in *cmd.Input) error {
+		return nil
+	})
+	assert.Nil(t, adapter.ScheduleHistory())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	session := &Session[testConfig]{App: appV1.NewMock[testConfig](nil), ctx: ctx}
+	assert.Equal(t, nil, adapter.RunSession(session, "schedule", "0 * * * *", "cleanup"))
+	assert.Equal(t, []cmd.JobRun{}, adapter.ScheduleHistory())
+
+	scheduler := adapter.scheduler.Load()
+	due := time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC)
+	scheduler.dispatch(context.Background(), scheduler.jobs[0], due)
+	scheduler.running.Wait()
+
+	history := adapter.ScheduleHistory()
+	assert.Len(t, history, 1)
+	assert.Equal(t, "cleanup", history[0].Command)
+	assert.Equal(t, due, history[0].ScheduledAt)
+}
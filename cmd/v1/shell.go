@@ -0,0 +1,280 @@
+package v1
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ampliway/way-lib-go/app"
+	"github.com/ampliway/way-lib-go/cmd"
+	"golang.org/x/term"
+)
+
+const (
+	shellContinuePrompt = "... "
+	shellExit           = "exit"
+	shellQuit           = "quit"
+)
+
+// lineReader reads what is typed in the shell. On a terminal lines are edited
+// with history and completion; otherwise they are read as they come.
+type lineReader interface {
+	ReadLine() (string, error)
+	SetPrompt(prompt string)
+}
+
+// shell runs the lines read from in.Stdin as commands, all sharing app, until
+// "exit", "quit" or the end of the input. A failing command is reported and
+// the shell goes on.
+func (c *Cmd[T]) shell(app app.V1[T], in *cmd.Input) error {
+	nodes, err := completionNodes(c.configs)
+	if err != nil {
+		return err
+	}
+
+	reader := c.lineReader(in, nodes)
+	prompt := c.program + "> "
+
+	for {
+		line, err := readCommand(reader, prompt)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		args, err := shellSplit(line)
+		if err != nil {
+			printError(in.Stderr, err)
+
+			continue
+		}
+
+		if len(args) == 0 {
+			continue
+		}
+
+		if args[0] == shellExit || args[0] == shellQuit {
+			return nil
+		}
+
+		if match, _, _ := c.findConfig(args...); match == c.shellConfig {
+			printError(in.Stderr, fmt.Errorf("%s: %w", cmd.MODULE_NAME, errShellNested))
+
+			continue
+		}
+
+		args = append(append([]string{}, in.ConfigArgs...), args...)
+		printError(in.Stderr, c.run(&Session[T]{App: app, Stdin: in.Stdin, Stdout: in.Stdout, Stderr: in.Stderr}, args))
+	}
+}
+
+func (c *Cmd[T]) lineReader(in *cmd.Input, nodes []completionNode) lineReader {
+	file, ok := in.Stdin.(*os.File)
+	if !ok || !term.IsTerminal(int(file.Fd())) {
+		return &plainReader{reader: bufio.NewReader(in.Stdin)}
+	}
+
+	terminal := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{file, in.Stdout}, "")
+
+	terminal.AutoCompleteCallback = func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+
+		return completeLine(terminal, nodes, line, pos)
+	}
+
+	return &terminalReader{terminal: terminal, fd: int(file.Fd())}
+}
+
+// readCommand reads lines until they form a whole command: a line ending with
+// a backslash or inside a quote goes on on the next one.
+func readCommand(reader lineReader, prompt string) (string, error) {
+	reader.SetPrompt(prompt)
+
+	command := ""
+
+	for {
+		line, err := reader.ReadLine()
+		if err != nil {
+			return "", err
+		}
+
+		trimmed := strings.TrimRight(line, "\\")
+		if (len(line)-len(trimmed))%2 == 1 {
+			command += line[:len(line)-1]
+			reader.SetPrompt(shellContinuePrompt)
+
+			continue
+		}
+
+		command += line
+
+		if _, err := shellSplit(command); errors.Is(err, errUnterminatedQuote) {
+			command += "\n"
+			reader.SetPrompt(shellContinuePrompt)
+
+			continue
+		}
+
+		return command, nil
+	}
+}
+
+// completeLine completes the word under the cursor with the subcommands and
+// flags of the command typed before it. When several words match and none can
+// be completed further, they are listed above the prompt.
+func completeLine(w io.Writer, nodes []completionNode, line string, pos int) (string, int, bool) {
+	head := line[:pos]
+	words := strings.Fields(head)
+
+	current := ""
+	if len(words) > 0 && !strings.HasSuffix(head, " ") {
+		current, words = words[len(words)-1], words[:len(words)-1]
+	}
+
+	path, positional, ok := completionPath(nodes, words)
+	if !ok {
+		return "", 0, false
+	}
+
+	candidates := completionCandidates(nodes, path, current, positional)
+	if len(candidates) == 0 {
+		return "", 0, false
+	}
+
+	completion := candidates[0]
+	if len(candidates) == 1 {
+		completion += " "
+	}
+
+	for _, candidate := range candidates[1:] {
+		for !strings.HasPrefix(candidate, completion) {
+			completion = completion[:len(completion)-1]
+		}
+	}
+
+	if completion == current {
+		fmt.Fprintln(w, strings.Join(candidates, "  "))
+
+		return "", 0, false
+	}
+
+	start := len(head) - len(current)
+
+	return line[:start] + completion + line[pos:], start + len(completion), true
+}
+
+// completionPath rebuilds the command path from words like the completion
+// scripts: flags are skipped with their value and the first positional arg
+// ends the path. It is not ok when a flag value or an arg after "--" is being
+// typed.
+func completionPath(nodes []completionNode, words []string) (string, bool, bool) {
+	paths, values := commandPaths(nodes), valueFlags(nodes)
+	path, positional := "", false
+
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+
+		switch {
+		case word == flagTerminator:
+			return "", false, false
+		case strings.HasPrefix(word, flagSeparator):
+			if !strings.Contains(word, "=") && (word == outputFlag || containsString(values, path+" "+word)) {
+				i++
+			}
+		case !positional && containsString(paths, strings.TrimSpace(path+" "+word)):
+			path = strings.TrimSpace(path + " " + word)
+		default:
+			positional = true
+		}
+
+		if i == len(words) {
+			return "", false, false
+		}
+	}
+
+	return path, positional, true
+}
+
+// completionCandidates lists the words of path starting with prefix, only its
+// flags after a positional arg.
+func completionCandidates(nodes []completionNode, path, prefix string, flagsOnly bool) []string {
+	result := []string{}
+
+	for _, node := range nodes {
+		if !containsString(node.paths, path) {
+			continue
+		}
+
+		for _, word := range node.words {
+			if (word.flag || !flagsOnly) && strings.HasPrefix(word.word, prefix) {
+				result = append(result, word.word)
+			}
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// terminalReader puts the terminal in raw mode only while a line is read, so
+// commands print and get signals as usual.
+type terminalReader struct {
+	terminal *term.Terminal
+	fd       int
+}
+
+func (r *terminalReader) ReadLine() (string, error) {
+	if width, height, err := term.GetSize(r.fd); err == nil && width > 0 {
+		_ = r.terminal.SetSize(width, height)
+	}
+
+	state, err := term.MakeRaw(r.fd)
+	if err != nil {
+		return "", err
+	}
+	defer term.Restore(r.fd, state)
+
+	return r.terminal.ReadLine()
+}
+
+func (r *terminalReader) SetPrompt(prompt string) {
+	r.terminal.SetPrompt(prompt)
+}
+
+type plainReader struct {
+	reader *bufio.Reader
+}
+
+func (r *plainReader) ReadLine() (string, error) {
+	line, err := r.reader.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (r *plainReader) SetPrompt(prompt string) {}
@@ -0,0 +1,98 @@
+package v1
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type promptRecorder struct {
+	plainReader
+	prompts []string
+}
+
+func (r *promptRecorder) SetPrompt(prompt string) {
+	r.prompts = append(r.prompts, prompt)
+}
+
+func TestReadCommand(t *testing.T) {
+	t.Parallel()
+
+	reader := &promptRecorder{plainReader: plainReader{reader: bufio.NewReader(strings.NewReader("" +
+		"topics list \\\n" +
+		"  --limit 2\n" +
+		"topics 'a\n" +
+		"b'\n" +
+		"echo a\\\\\n" +
+		"last"))}}
+
+	tableTest := []struct {
+		Expected        string
+		ExpectedPrompts []string
+	}{
+		{Expected: "topics list   --limit 2", ExpectedPrompts: []string{"tool> ", "... "}},
+		{Expected: "topics 'a\nb'", ExpectedPrompts: []string{"tool> ", "... "}},
+		{Expected: "echo a\\\\", ExpectedPrompts: []string{"tool> "}},
+		{Expected: "last", ExpectedPrompts: []string{"tool> "}},
+	}
+
+	for _, rowTest := range tableTest {
+		reader.prompts = nil
+
+		command, err := readCommand(reader, "tool> ")
+		assert.Equal(t, nil, err)
+		assert.Equal(t, rowTest.Expected, command)
+		assert.Equal(t, rowTest.ExpectedPrompts, reader.prompts)
+	}
+
+	_, err := readCommand(reader, "tool> ")
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestCompleteLine(t *testing.T) {
+	t.Parallel()
+
+	nodes, err := completionNodes(newHelpAdapter(t).configs)
+	assert.Equal(t, nil, err)
+
+	tableTest := []struct {
+		Scenario       string
+		Line           string
+		Pos            int
+		ExpectedLine   string
+		ExpectedPos    int
+		ExpectedOk     bool
+		ExpectedListed string
+	}{
+		{Scenario: "command", Line: "to", Pos: 2, ExpectedLine: "topics ", ExpectedPos: 7, ExpectedOk: true},
+		{Scenario: "subcommand", Line: "t l", Pos: 3, ExpectedListed: "list  ls\n"},
+		{Scenario: "flag", Line: "topics list --li", Pos: 16, ExpectedLine: "topics list --limit ", ExpectedPos: 20, ExpectedOk: true},
+		{Scenario: "common_prefix", Line: "t list --", Pos: 9, ExpectedListed: "--dry-run  --filter  --help  --limit\n"},
+		{Scenario: "middle", Line: "top list", Pos: 3, ExpectedLine: "topics  list", ExpectedPos: 7, ExpectedOk: true},
+		{Scenario: "no_match", Line: "queues", Pos: 6},
+		{Scenario: "after_flag_value", Line: "t list --limit 5 --dr", Pos: 21, ExpectedLine: "t list --limit 5 --dry-run ", ExpectedPos: 27, ExpectedOk: true},
+		{Scenario: "flag_value", Line: "t list --limit ", Pos: 15},
+		{Scenario: "after_positional", Line: "t list ls l", Pos: 11},
+		{Scenario: "after_terminator", Line: "t list -- --", Pos: 12},
+	}
+
+	for _, rowTest := range tableTest {
+		rowTest := rowTest
+
+		t.Run(rowTest.Scenario, func(t *testing.T) {
+			t.Parallel()
+
+			listed := &bytes.Buffer{}
+
+			line, pos, ok := completeLine(listed, nodes, rowTest.Line, rowTest.Pos)
+			assert.Equal(t, rowTest.ExpectedLine, line)
+			assert.Equal(t, rowTest.ExpectedPos, pos)
+			assert.Equal(t, rowTest.ExpectedOk, ok)
+			assert.Equal(t, rowTest.ExpectedListed, listed.String())
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ampliway/way-lib-go/app"
+	"github.com/ampliway/way-lib-go/cmd"
+)
+
+const signalExitBase = 128
+
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// executeWithSignals runs execute until it returns or SIGINT/SIGTERM arrives.
+func (c *Cmd[T]) executeWithSignals(appModule app.V1[T], owned bool, path []string, execute cmd.Handler[T], in *cmd.Input) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, shutdownSignals...)
+	defer signal.Stop(signals)
+
+	return c.execute(context.Background(), signals, appModule, owned, path, execute, in)
+}
+
+// execute hands the command a context cancelled by the first signal, or with
+// parent, and waits up to the grace period for it to return, or less on a
+// second signal. An owned app is then shut down, with the same grace period.
+func (c *Cmd[T]) execute(parent context.Context, signals <-chan os.Signal, appModule app.V1[T], owned bool, path []string, execute cmd.Handler[T], in *cmd.Input) error {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	done := make(chan error, 1)
+
+	go func() {
+		done <- execute(appModule, in.WithContext(ctx))
+	}()
+
+	var (
+		received os.Signal
+		err      error
+	)
+
+	select {
+	case err = <-done:
+	case received = <-signals:
+		cancel()
+
+		timer := time.NewTimer(c.settings.gracePeriod)
+		defer timer.Stop()
+
+		select {
+		case err = <-done:
+		case <-timer.C:
+			err = fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errGracePeriod, c.settings.gracePeriod)
+		case <-signals:
+			err = fmt.Errorf("%s: %w", cmd.MODULE_NAME, errForced)
+		}
+	}
+
+	errs := []error{}
+
+	if received != nil {
+		errs = append(errs, fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errInterrupted, received))
+	}
+
+	if err != nil {
+		errs = append(errs, fmt.Errorf("%s: %w: %s: %w", cmd.MODULE_NAME, errExecutionFailed, strings.Join(path, " "), err))
+	}
+
+	if owned {
+		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), c.settings.gracePeriod)
+		defer shutdownCancel()
+
+		if err := appModule.Shutdown(shutdownCtx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w: %w", cmd.MODULE_NAME, errShutdown, err))
+		}
+	}
+
+	result := errors.Join(errs...)
+
+	if signum, ok := received.(syscall.Signal); ok {
+		return cmd.Exit(signalExitBase+int(signum), result)
+	}
+
+	return result
+}
@@ -0,0 +1,126 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/ampliway/way-lib-go/app"
+	"github.com/ampliway/way-lib-go/cache"
+	"github.com/ampliway/way-lib-go/cmd"
+	"github.com/ampliway/way-lib-go/msg"
+	"github.com/ampliway/way-lib-go/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+type shutdownApp struct {
+	shutdownErr error
+	shutdown    bool
+}
+
+func (a *shutdownApp) Config() *testConfig { return &testConfig{} }
+func (a *shutdownApp) Msg() msg.ProducerV1 { return nil }
+func (a *shutdownApp) Storage() storage.V1 { return nil }
+func (a *shutdownApp) Cache() cache.V1     { return nil }
+func (a *shutdownApp) ID() string          { return "id" }
+
+func (a *shutdownApp) Shutdown(ctx context.Context) error {
+	a.shutdown = true
+
+	return a.shutdownErr
+}
+
+func TestExecute(t *testing.T) {
+	t.Parallel()
+
+	waitCancel := func(app app.V1[testConfig], in *cmd.Input) error {
+		<-in.Context().Done()
+
+		return nil
+	}
+
+	block := func(app app.V1[testConfig], in *cmd.Input) error {
+		select {}
+	}
+
+	tableTest := []struct {
+		Scenario     string
+		Execute      func(app app.V1[testConfig], in *cmd.Input) error
+		Signals      []os.Signal
+		ShutdownErr  error
+		ExpectedErr  string
+		ExpectedCode int
+	}{
+		{
+			Scenario: "success",
+			Execute: func(app app.V1[testConfig], in *cmd.Input) error {
+				return nil
+			},
+			ExpectedCode: cmd.ExitOK,
+		},
+		{
+			Scenario: "failure_and_shutdown_error",
+			Execute: func(app app.V1[testConfig], in *cmd.Input) error {
+				return errors.New("boom")
+			},
+			ShutdownErr:  errors.New("redis closed"),
+			ExpectedErr:  "cmd: execution failed: job: boom\ncmd: shutdown failed: redis closed",
+			ExpectedCode: cmd.ExitFailure,
+		},
+		{
+			Scenario:     "interrupted",
+			Execute:      waitCancel,
+			Signals:      []os.Signal{syscall.SIGINT},
+			ExpectedErr:  "cmd: interrupted: interrupt",
+			ExpectedCode: 130,
+		},
+		{
+			Scenario:     "grace_period",
+			Execute:      block,
+			Signals:      []os.Signal{syscall.SIGTERM},
+			ExpectedErr:  "cmd: interrupted: terminated\ncmd: execution failed: job: cmd: command did not return within the grace period: 20ms",
+			ExpectedCode: 143,
+		},
+		{
+			Scenario:     "second_signal",
+			Execute:      block,
+			Signals:      []os.Signal{syscall.SIGINT, syscall.SIGINT},
+			ExpectedErr:  "cmd: interrupted: interrupt\ncmd: execution failed: job: cmd: command abandoned on second signal",
+			ExpectedCode: 130,
+		},
+	}
+
+	for _, rowTest := range tableTest {
+		rowTest := rowTest
+
+		t.Run(rowTest.Scenario, func(t *testing.T) {
+			t.Parallel()
+
+			adapter := New[testConfig](WithGracePeriod(20 * time.Millisecond))
+			if len(rowTest.Signals) > 1 {
+				adapter = New[testConfig](WithGracePeriod(time.Hour))
+			}
+
+			signals := make(chan os.Signal, len(rowTest.Signals))
+			for _, s := range rowTest.Signals {
+				signals <- s
+			}
+
+			fakeApp := &shutdownApp{shutdownErr: rowTest.ShutdownErr}
+			err := adapter.execute(context.Background(), signals, fakeApp, true, []string{"job"}, rowTest.Execute, &cmd.Input{})
+			assert.True(t, fakeApp.shutdown)
+			assert.Equal(t, rowTest.ExpectedCode, cmd.ExitCode(err))
+
+			if rowTest.ExpectedErr == "" {
+				assert.Equal(t, nil, err)
+
+				return
+			}
+
+			assert.Equal(t, rowTest.ExpectedErr, err.Error())
+		})
+	}
+}
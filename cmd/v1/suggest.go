@@ -0,0 +1,91 @@
+package v1
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ampliway/way-lib-go/cmd"
+)
+
+const suggestMaxDistance = 2
+
+// unknownCommand reports name as unknown among configs, suggesting the names
+// and aliases starting with it or within a short edit distance, shorter than
+// the candidate itself so "ls" is not offered for "rm".
+func unknownCommand[T any](configs []*cmd.Config[T], name string) error {
+	suggestions := suggest(configs, name)
+	if len(suggestions) == 0 {
+		return fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errUnknown, name)
+	}
+
+	return fmt.Errorf("%s: %w: %s, did you mean %s?", cmd.MODULE_NAME, errUnknown, name, strings.Join(suggestions, " or "))
+}
+
+func suggest[T any](configs []*cmd.Config[T], name string) []string {
+	type candidate struct {
+		name     string
+		distance int
+	}
+
+	candidates := []candidate{}
+
+	for _, config := range configs {
+		for _, configName := range configNames(config) {
+			distance := levenshtein(strings.ToLower(name), strings.ToLower(configName))
+			if (distance <= suggestMaxDistance && distance < len(configName)) || strings.HasPrefix(configName, name) {
+				candidates = append(candidates, candidate{name: configName, distance: distance})
+			}
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	result := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		result = append(result, fmt.Sprintf("%q", c.name))
+	}
+
+	return result
+}
+
+func levenshtein(a, b string) int {
+	source, target := []rune(a), []rune(b)
+
+	previous := make([]int, len(target)+1)
+	current := make([]int, len(target)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(target)]
+}
+
+func minInt(values ...int) int {
+	result := values[0]
+	for _, value := range values[1:] {
+		if value < result {
+			result = value
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,28 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevenshtein(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, 0, levenshtein("", ""))
+	assert.Equal(t, 3, levenshtein("", "abc"))
+	assert.Equal(t, 1, levenshtein("tpics", "topics"))
+	assert.Equal(t, 3, levenshtein("kitten", "sitting"))
+	assert.Equal(t, 1, levenshtein("ação", "acão"))
+}
+
+func TestUnknownCommand(t *testing.T) {
+	t.Parallel()
+
+	configs := newHelpAdapter(t).configs
+
+	assert.Equal(t, `cmd: unknown command: tpics, did you mean "topics"?`, unknownCommand(configs, "tpics").Error())
+	assert.Equal(t, `cmd: unknown command: top, did you mean "topics"?`, unknownCommand(configs, "top").Error())
+	assert.Equal(t, `cmd: unknown command: lst, did you mean "list" or "ls"?`, unknownCommand(configs[0].Commands, "lst").Error())
+	assert.Equal(t, "cmd: unknown command: rm", unknownCommand(configs[0].Commands, "rm").Error())
+}
@@ -0,0 +1,229 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ampliway/way-lib-go/app"
+	"github.com/ampliway/way-lib-go/cmd"
+)
+
+// serve adapts config.Workers to an Execute function supervising them.
+func (c *Cmd[T]) serve(config *cmd.Config[T]) cmd.Handler[T] {
+	return func(app app.V1[T], in *cmd.Input) error {
+		workers, err := config.Workers(app, in)
+		if err != nil {
+			return err
+		}
+
+		stderr := in.Stderr
+		if stderr == nil {
+			stderr = io.Discard
+		}
+
+		return supervise(in.Context(), workers, c.settings, stderr)
+	}
+}
+
+// lockedWriter serializes the writes of concurrent workers.
+type lockedWriter struct {
+	mux sync.Mutex
+	w   io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.mux.Lock()
+	defer w.mux.Unlock()
+
+	return w.w.Write(p)
+}
+
+// supervise runs the workers concurrently until ctx is done, a critical worker
+// fails or every worker returns, reporting restarts to stderr. Then every
+// worker is stopped, waiting for them up to the grace period.
+func supervise(ctx context.Context, workers []*cmd.WorkerConfig, s settings, stderr io.Writer) error {
+	for _, worker := range workers {
+		if worker == nil || worker.Worker == nil {
+			return fmt.Errorf("%s: %w", cmd.MODULE_NAME, errWorkerNil)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mux       sync.Mutex
+		critical  error
+		waitGroup sync.WaitGroup
+	)
+
+	stderr = &lockedWriter{w: stderr}
+
+	for _, worker := range workers {
+		waitGroup.Add(1)
+
+		go func(worker *cmd.WorkerConfig) {
+			defer waitGroup.Done()
+
+			if err := runWorker(ctx, worker, s, stderr); err != nil {
+				mux.Lock()
+				if critical == nil {
+					critical = err
+				}
+				mux.Unlock()
+
+				cancel()
+			}
+		}(worker)
+	}
+
+	stopped := make(chan struct{})
+
+	go func() {
+		waitGroup.Wait()
+		close(stopped)
+		cancel()
+	}()
+
+	<-ctx.Done()
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), s.gracePeriod)
+	defer stopCancel()
+
+	errs := stopWorkers(stopCtx, workers)
+
+	select {
+	case <-stopped:
+	case <-stopCtx.Done():
+		select {
+		case <-stopped:
+		default:
+			errs = append(errs, fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errWorkerStuck, s.gracePeriod))
+		}
+	}
+
+	mux.Lock()
+	defer mux.Unlock()
+
+	return errors.Join(append([]error{critical}, errs...)...)
+}
+
+// runWorker starts worker again after each failure, doubling the wait from
+// the minimum up to the maximum backoff. A run longer than the maximum backoff
+// resets the wait. It returns an error only when a critical worker fails.
+func runWorker(ctx context.Context, worker *cmd.WorkerConfig, s settings, stderr io.Writer) error {
+	backoff := s.restartBackoffMin
+
+	for {
+		started := time.Now()
+
+		err := startWorker(ctx, worker)
+		if err == nil || ctx.Err() != nil {
+			return nil
+		}
+
+		if worker.Critical {
+			return fmt.Errorf("%s: %w: %s: %w", cmd.MODULE_NAME, errWorkerCritical, worker.Name, err)
+		}
+
+		if time.Since(started) > s.restartBackoffMax {
+			backoff = s.restartBackoffMin
+		}
+
+		fmt.Fprintf(stderr, "%s: worker %s failed, restarting in %s: %v\n", cmd.MODULE_NAME, worker.Name, backoff, err)
+
+		timer := time.NewTimer(backoff)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil
+		case <-timer.C:
+		}
+
+		backoff *= 2
+		if backoff > s.restartBackoffMax {
+			backoff = s.restartBackoffMax
+		}
+	}
+}
+
+func startWorker(ctx context.Context, worker *cmd.WorkerConfig) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("%w: %v", errWorkerPanic, recovered)
+		}
+	}()
+
+	return worker.Worker.Start(ctx)
+}
+
+// stopWorkers stops the workers concurrently, giving up on the ones still
+// stopping when ctx is done.
+func stopWorkers(ctx context.Context, workers []*cmd.WorkerConfig) []error {
+	var (
+		mux       sync.Mutex
+		errs      []error
+		waitGroup sync.WaitGroup
+	)
+
+	// filled before any Stop runs: the goroutines delete from it as they end
+	stopping := map[int]string{}
+	for i, worker := range workers {
+		stopping[i] = worker.Name
+	}
+
+	for i, worker := range workers {
+		waitGroup.Add(1)
+
+		go func(i int, worker *cmd.WorkerConfig) {
+			defer waitGroup.Done()
+
+			err := worker.Worker.Stop(ctx)
+
+			mux.Lock()
+			defer mux.Unlock()
+
+			delete(stopping, i)
+
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w: %s: %w", cmd.MODULE_NAME, errWorkerStop, worker.Name, err))
+			}
+		}(i, worker)
+	}
+
+	stopped := make(chan struct{})
+
+	go func() {
+		waitGroup.Wait()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+	}
+
+	mux.Lock()
+	defer mux.Unlock()
+
+	if len(stopping) > 0 {
+		names := make([]string, 0, len(stopping))
+		for _, name := range stopping {
+			names = append(names, name)
+		}
+
+		sort.Strings(names)
+
+		errs = append(errs, fmt.Errorf("%s: %w: %s", cmd.MODULE_NAME, errWorkerStopStuck, strings.Join(names, ", ")))
+	}
+
+	return append([]error{}, errs...)
+}
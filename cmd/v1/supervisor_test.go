@@ -0,0 +1,212 @@
+package v1
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ampliway/way-lib-go/app"
+	"github.com/ampliway/way-lib-go/cmd"
+	"github.com/stretchr/testify/assert"
+)
+
+type funcWorker struct {
+	start   func(ctx context.Context) error
+	stop    func(ctx context.Context) error
+	stopErr error
+	starts  atomic.Int32
+	stops   atomic.Int32
+}
+
+func (w *funcWorker) Start(ctx context.Context) error {
+	w.starts.Add(1)
+
+	return w.start(ctx)
+}
+
+func (w *funcWorker) Stop(ctx context.Context) error {
+	w.stops.Add(1)
+
+	if w.stop != nil {
+		return w.stop(ctx)
+	}
+
+	return w.stopErr
+}
+
+func untilDone(ctx context.Context) error {
+	<-ctx.Done()
+
+	return nil
+}
+
+func testSettings() settings {
+	s := defaultSettings()
+	WithGracePeriod(50 * time.Millisecond)(&s)
+	WithRestartBackoff(time.Millisecond, 4*time.Millisecond)(&s)
+
+	return s
+}
+
+func TestSupervise_Restart(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	flaky := &funcWorker{}
+	flaky.start = func(ctx context.Context) error {
+		if flaky.starts.Load() < 3 {
+			return errors.New("connection reset")
+		}
+
+		if flaky.starts.Load() == 3 {
+			panic("nil map")
+		}
+
+		cancel()
+
+		return untilDone(ctx)
+	}
+
+	steady := &funcWorker{start: untilDone}
+	stderr := &bytes.Buffer{}
+
+	err := supervise(ctx, []*cmd.WorkerConfig{
+		{Name: "consumer", Worker: flaky},
+		{Name: "http", Worker: steady, Critical: true},
+	}, testSettings(), stderr)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 3, strings.Count(stderr.String(), "cmd: worker consumer failed, restarting in "))
+	assert.Contains(t, stderr.String(), ": worker panicked: nil map\n")
+	assert.Equal(t, int32(4), flaky.starts.Load())
+	assert.Equal(t, int32(1), steady.starts.Load())
+	assert.Equal(t, int32(1), flaky.stops.Load())
+	assert.Equal(t, int32(1), steady.stops.Load())
+}
+
+func TestSupervise_Critical(t *testing.T) {
+	t.Parallel()
+
+	steady := &funcWorker{start: untilDone, stopErr: errors.New("listener closed")}
+	critical := &funcWorker{start: func(ctx context.Context) error {
+		return errors.New("port in use")
+	}}
+
+	err := supervise(context.Background(), []*cmd.WorkerConfig{
+		{Name: "consumer", Worker: steady},
+		{Name: "http", Worker: critical, Critical: true},
+	}, testSettings(), io.Discard)
+	assert.Equal(t, "cmd: critical worker failed: http: port in use\ncmd: worker stop failed: consumer: listener closed", err.Error())
+	assert.Equal(t, int32(1), critical.starts.Load())
+	assert.Equal(t, int32(1), steady.stops.Load())
+}
+
+func TestSupervise_AllDone(t *testing.T) {
+	t.Parallel()
+
+	once := &funcWorker{start: func(ctx context.Context) error {
+		return nil
+	}}
+
+	assert.Equal(t, nil, supervise(context.Background(), []*cmd.WorkerConfig{{Name: "migrate", Worker: once}}, testSettings(), io.Discard))
+	assert.Equal(t, int32(1), once.stops.Load())
+}
+
+func TestSupervise_Stuck(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var release sync.WaitGroup
+	release.Add(1)
+	defer release.Done()
+
+	stuck := &funcWorker{start: func(ctx context.Context) error {
+		release.Wait()
+
+		return nil
+	}}
+
+	err := supervise(ctx, []*cmd.WorkerConfig{{Name: "stuck", Worker: stuck}}, testSettings(), io.Discard)
+	assert.Equal(t, "cmd: workers did not stop within the grace period: 50ms", err.Error())
+
+	err = supervise(ctx, []*cmd.WorkerConfig{{Name: "nil"}}, testSettings(), io.Discard)
+	assert.Equal(t, "cmd: worker cannot be nil", err.Error())
+}
+
+func TestSupervise_StuckStop(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	release := make(chan struct{})
+	defer close(release)
+
+	stuck := &funcWorker{start: untilDone, stop: func(ctx context.Context) error {
+		<-release
+
+		return nil
+	}}
+	steady := &funcWorker{start: untilDone}
+
+	started := time.Now()
+	err := supervise(ctx, []*cmd.WorkerConfig{
+		{Name: "consumer", Worker: stuck},
+		{Name: "http", Worker: steady},
+	}, testSettings(), io.Discard)
+	assert.Equal(t, "cmd: worker stop did not return within the grace period: consumer", err.Error())
+	assert.Less(t, time.Since(started), time.Second)
+}
+
+func TestStopWorkers(t *testing.T) {
+	t.Parallel()
+
+	workers := []*cmd.WorkerConfig{}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		workers = append(workers, &cmd.WorkerConfig{Name: name, Worker: &funcWorker{stopErr: errors.New("closed")}})
+	}
+
+	errs := stopWorkers(context.Background(), workers)
+	assert.Len(t, errs, len(workers))
+
+	for _, err := range errs {
+		assert.Contains(t, err.Error(), ": closed")
+	}
+}
+
+func TestServe(t *testing.T) {
+	t.Parallel()
+
+	adapter := New[testConfig](WithGracePeriod(50 * time.Millisecond))
+	worker := &funcWorker{start: untilDone}
+
+	config := &cmd.Config[testConfig]{
+		Name:        "serve",
+		Description: "Serve",
+		Workers: func(app app.V1[testConfig], in *cmd.Input) ([]*cmd.WorkerConfig, error) {
+			return []*cmd.WorkerConfig{{Name: "http", Worker: worker}}, nil
+		},
+	}
+	assert.Equal(t, nil, adapter.Add(config))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	in := (&cmd.Input{}).WithContext(ctx)
+	assert.Equal(t, nil, adapter.serve(config)(&shutdownApp{}, in))
+	assert.Equal(t, int32(1), worker.stops.Load())
+
+	config.Execute = func(app app.V1[testConfig], in *cmd.Input) error {
+		return nil
+	}
+	assert.Equal(t, "cmd: config cannot have both execute and workers", New[testConfig]().Add(config).Error())
+}
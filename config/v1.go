@@ -5,3 +5,27 @@ const MODULE_NAME = "config"
 type V1[T any] interface {
 	Get() *T
 }
+
+type ReloadableV1[T any] interface {
+	V1[T]
+	OnChange(handler func(old, new *T))
+	OnError(handler func(err error))
+	Reload() error
+	Close()
+}
+
+type SecretResolver interface {
+	Scheme() string
+	Resolve(name string) (string, error)
+}
+
+type Source interface {
+	Lookup(name string) (string, bool, error)
+}
+
+// SnapshotSource is a Source able to read all its variables at once: a load
+// then fetches it a single time instead of once per variable.
+type SnapshotSource interface {
+	Source
+	Snapshot() (map[string]string, error)
+}
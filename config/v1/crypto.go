@@ -0,0 +1,175 @@
+package v1
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/ampliway/way-lib-go/config"
+)
+
+const (
+	EncryptedPrefix = "enc:"
+	KeyFileVar      = "CONFIG_KEY_FILE"
+
+	keySize     = 32
+	ruleDecrypt = "decrypt"
+
+	valueDelimiters = " \t\r\n\"',[]{}"
+)
+
+var (
+	errKeyFileNotSet    = errors.New("encrypted value found but " + KeyFileVar + " is not set")
+	errKeyRead          = errors.New("could not read key file")
+	errKeyInvalid       = fmt.Errorf("key must be %d bytes encoded as base64", keySize)
+	errDecrypt          = errors.New("could not decrypt value")
+	errEncryptedInvalid = errors.New("invalid encrypted value")
+
+	sealedEncoding  = base64.RawURLEncoding
+	encryptedValues = regexp.MustCompile(regexp.QuoteMeta(EncryptedPrefix) + `[A-Za-z0-9_-]+`)
+	dotenvKey       = regexp.MustCompile(`^[ \t]*(export[ \t]+)?[A-Za-z_][A-Za-z0-9_.]*$`)
+)
+
+// GenerateKey returns a new random key in the base64 form stored in key files.
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+
+	return []byte(base64.StdEncoding.EncodeToString(key) + "\n"), nil
+}
+
+// ParseKey decodes the content of a key file.
+func ParseKey(data []byte) ([]byte, error) {
+	key, err := parseKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", config.MODULE_NAME, err)
+	}
+
+	return key, nil
+}
+
+func parseKey(data []byte) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(data)))
+	if err != nil || len(key) != keySize {
+		return nil, errKeyInvalid
+	}
+
+	return key, nil
+}
+
+// Encrypt seals value with AES-256-GCM and returns it as "enc:<base64>".
+func Encrypt(key []byte, value string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", config.MODULE_NAME, err)
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	sealed := aead.Seal(nonce, nonce, []byte(value), nil)
+
+	return EncryptedPrefix + sealedEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt.
+func Decrypt(key []byte, value string) (string, error) {
+	plain, err := decrypt(key, value)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", config.MODULE_NAME, err)
+	}
+
+	return plain, nil
+}
+
+func decrypt(key []byte, value string) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	sealed, err := sealedEncoding.DecodeString(strings.TrimPrefix(value, EncryptedPrefix))
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errEncryptedInvalid
+	}
+
+	plain, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], nil)
+	if err != nil {
+		return "", errDecrypt
+	}
+
+	return string(plain), nil
+}
+
+// Rotate re-encrypts every "enc:" value found in data from oldKey to newKey,
+// leaving the rest of the file untouched. Only whole values are rotated, not
+// an "enc:" inside a longer word.
+func Rotate(oldKey, newKey, data []byte) ([]byte, error) {
+	result := bytes.Buffer{}
+	last := 0
+
+	for _, match := range encryptedValues.FindAllIndex(data, -1) {
+		start, end := match[0], match[1]
+		if !isWholeValue(data, start, end) {
+			continue
+		}
+
+		plain, err := Decrypt(oldKey, string(data[start:end]))
+		if err != nil {
+			return nil, err
+		}
+
+		sealed, err := Encrypt(newKey, plain)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Write(data[last:start])
+		result.WriteString(sealed)
+		last = end
+	}
+
+	result.Write(data[last:])
+
+	return result.Bytes(), nil
+}
+
+// isWholeValue reports whether data[start:end] stands alone: delimited by
+// blanks, quotes, commas or brackets, or following the "=" of a dotenv key.
+func isWholeValue(data []byte, start, end int) bool {
+	if end < len(data) && !strings.ContainsRune(valueDelimiters, rune(data[end])) {
+		return false
+	}
+
+	if start == 0 {
+		return true
+	}
+
+	if data[start-1] == '=' {
+		lineStart := bytes.LastIndexByte(data[:start-1], '\n') + 1
+
+		return dotenvKey.Match(data[lineStart : start-1])
+	}
+
+	return strings.ContainsRune(valueDelimiters, rune(data[start-1]))
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil || len(key) != keySize {
+		return nil, errKeyInvalid
+	}
+
+	return cipher.NewGCM(block)
+}
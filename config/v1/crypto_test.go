@@ -0,0 +1,113 @@
+package v1
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	t.Parallel()
+
+	keyData, err := GenerateKey()
+	assert.Equal(t, nil, err)
+
+	key, err := ParseKey(keyData)
+	assert.Equal(t, nil, err)
+	assert.Len(t, key, keySize)
+
+	sealed, err := Encrypt(key, "p@ss")
+	assert.Equal(t, nil, err)
+	assert.True(t, strings.HasPrefix(sealed, EncryptedPrefix))
+
+	plain, err := Decrypt(key, sealed)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "p@ss", plain)
+
+	otherData, _ := GenerateKey()
+	otherKey, _ := ParseKey(otherData)
+
+	_, err = Decrypt(otherKey, sealed)
+	assert.Equal(t, "config: could not decrypt value", err.Error())
+
+	_, err = Decrypt(key, "enc:***")
+	assert.Equal(t, "config: invalid encrypted value", err.Error())
+
+	_, err = ParseKey([]byte("short"))
+	assert.Equal(t, "config: key must be 32 bytes encoded as base64", err.Error())
+
+	_, err = Encrypt([]byte("short"), "p@ss")
+	assert.Equal(t, "config: key must be 32 bytes encoded as base64", err.Error())
+}
+
+func TestRotate(t *testing.T) {
+	t.Parallel()
+
+	oldData, _ := GenerateKey()
+	oldKey, _ := ParseKey(oldData)
+	newData, _ := GenerateKey()
+	newKey, _ := ParseKey(newData)
+
+	first, _ := Encrypt(oldKey, "first")
+	second, _ := Encrypt(oldKey, "second")
+
+	rotated, err := Rotate(oldKey, newKey, []byte("a: "+first+"\nb: plain\nc: \""+second+"\"\n"))
+	assert.Equal(t, nil, err)
+
+	values, err := parseFile("config.yaml", rotated)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "plain", values["B"])
+
+	plain, err := Decrypt(newKey, values["A"])
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "first", plain)
+
+	plain, err = Decrypt(newKey, values["C"])
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "second", plain)
+
+	embedded := []byte("url: https://host/?token=" + first + "\nname: my" + first + "\nword: " + first + ".bak\n")
+	rotated, err = Rotate(oldKey, newKey, embedded)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, string(embedded), string(rotated))
+
+	rotated, err = Rotate(oldKey, newKey, []byte("FIELD_1="+first+"\nlist = ["+first+","+second+"]"))
+	assert.Equal(t, nil, err)
+	assert.NotContains(t, string(rotated), first)
+	assert.NotContains(t, string(rotated), second)
+
+	_, err = Rotate(newKey, oldKey, []byte("a: "+first))
+	assert.Equal(t, "config: could not decrypt value", err.Error())
+}
+
+func TestLoad_Encrypted(t *testing.T) {
+	t.Parallel()
+
+	keyData, _ := GenerateKey()
+	key, _ := ParseKey(keyData)
+	sealed, _ := Encrypt(key, "p@ss")
+
+	fsys := fstest.MapFS{
+		"config.env": {Data: []byte("FIELD_1=" + sealed + "\nFIELD_2=1\nFIELD_3=true\n")},
+		"key":        {Data: keyData},
+	}
+
+	loader := NewLoader([]string{"-f=config.env"}, LookupMap(map[string]string{KeyFileVar: "key"}), fsys)
+
+	env, err := Load[testConfig](loader)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &testConfig{Field1: "p@ss", Field2: 1, Field3: true}, env.Get())
+	assert.Equal(t, secretMask, env.Fields()[0].Value)
+
+	loadedKey, err := loader.Key()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, key, loadedKey)
+
+	_, err = Load[testConfig](NewLoader([]string{"-f=config.env"}, nil, fsys))
+	assert.Equal(t, "config: FIELD_1 (string, decrypt): encrypted value found but CONFIG_KEY_FILE is not set", err.Error())
+
+	_, err = NewLoader(nil, nil, fsys).Key()
+	assert.Equal(t, "config: encrypted value found but CONFIG_KEY_FILE is not set", err.Error())
+}
@@ -0,0 +1,238 @@
+package v1
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ampliway/way-lib-go/config"
+)
+
+const (
+	listSeparator     = ","
+	keyValueSeparator = "="
+)
+
+var (
+	errEnvUintParse     = errors.New("could not parse found value to unsigned integer")
+	errEnvFloatParse    = errors.New("could not parse found value to float")
+	errEnvDurationParse = errors.New("could not parse found value to duration")
+	errEnvURLParse      = errors.New("could not parse found value to url")
+	errEnvMapParse      = errors.New("could not parse found value to map, expected \"key=value\" pairs")
+	errEnvParse         = errors.New("could not parse found value")
+	errDecoderNil       = errors.New("decoder cannot be nil")
+
+	decodersMux sync.RWMutex
+	decoders    = map[reflect.Type]func(value string) (reflect.Value, error){}
+
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+func init() {
+	_ = RegisterDecoder(func(value string) (time.Duration, error) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return 0, errEnvDurationParse
+		}
+
+		return d, nil
+	})
+
+	_ = RegisterDecoder(func(value string) (*url.URL, error) {
+		u, err := url.Parse(value)
+		if err != nil {
+			return nil, errEnvURLParse
+		}
+
+		return u, nil
+	})
+
+	_ = RegisterDecoder(func(value string) (url.URL, error) {
+		u, err := url.Parse(value)
+		if err != nil {
+			return url.URL{}, errEnvURLParse
+		}
+
+		return *u, nil
+	})
+}
+
+// RegisterDecoder makes fields of type V decodable from their raw string value.
+// A registered decoder takes precedence over the built-in ones, so it can also
+// override how a standard type is parsed.
+func RegisterDecoder[V any](decoder func(value string) (V, error)) error {
+	if decoder == nil {
+		return fmt.Errorf("%s: %w", config.MODULE_NAME, errDecoderNil)
+	}
+
+	decodersMux.Lock()
+	defer decodersMux.Unlock()
+
+	decoders[reflect.TypeOf((*V)(nil)).Elem()] = func(value string) (reflect.Value, error) {
+		v, err := decoder(value)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		return reflect.ValueOf(&v).Elem(), nil
+	}
+
+	return nil
+}
+
+func findDecoder(t reflect.Type) (func(value string) (reflect.Value, error), bool) {
+	decodersMux.RLock()
+	defer decodersMux.RUnlock()
+
+	decoder, exist := decoders[t]
+
+	return decoder, exist
+}
+
+func isDecodable(t reflect.Type) bool {
+	if _, exist := findDecoder(t); exist {
+		return true
+	}
+
+	return t.Implements(textUnmarshalerType) || reflect.PointerTo(t).Implements(textUnmarshalerType)
+}
+
+func decode(field reflect.Value, value string) error {
+	t := field.Type()
+
+	if decoder, exist := findDecoder(t); exist {
+		decoded, err := decoder(value)
+		if err != nil {
+			return err
+		}
+
+		field.Set(decoded)
+
+		return nil
+	}
+
+	if t.Kind() == reflect.Ptr {
+		elem := reflect.New(t.Elem())
+		if err := decode(elem.Elem(), value); err != nil {
+			return err
+		}
+
+		field.Set(elem)
+
+		return nil
+	}
+
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		if err := field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(value)); err != nil {
+			return fmt.Errorf("%w: %w", errEnvParse, err)
+		}
+
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			return errEnvBoolParse
+		}
+		field.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(value, 10, t.Bits())
+		if err != nil {
+			return errEnvIntParse
+		}
+		field.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(value, 10, t.Bits())
+		if err != nil {
+			return errEnvUintParse
+		}
+		field.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(value, t.Bits())
+		if err != nil {
+			return errEnvFloatParse
+		}
+		field.SetFloat(v)
+	case reflect.Slice:
+		return decodeSlice(field, value)
+	case reflect.Map:
+		return decodeMap(field, value)
+	default:
+		return errFieldNotSupported
+	}
+
+	return nil
+}
+
+func decodeSlice(field reflect.Value, value string) error {
+	t := field.Type()
+	if t.Elem().Kind() == reflect.Uint8 {
+		field.SetBytes([]byte(value))
+
+		return nil
+	}
+
+	items := splitList(value)
+	slice := reflect.MakeSlice(t, len(items), len(items))
+
+	for i, item := range items {
+		if err := decode(slice.Index(i), item); err != nil {
+			return err
+		}
+	}
+
+	field.Set(slice)
+
+	return nil
+}
+
+func decodeMap(field reflect.Value, value string) error {
+	t := field.Type()
+	result := reflect.MakeMap(t)
+
+	for _, item := range splitList(value) {
+		pair := strings.SplitN(item, keyValueSeparator, 2)
+		if len(pair) != 2 {
+			return errEnvMapParse
+		}
+
+		key := reflect.New(t.Key()).Elem()
+		if err := decode(key, strings.TrimSpace(pair[0])); err != nil {
+			return err
+		}
+
+		elem := reflect.New(t.Elem()).Elem()
+		if err := decode(elem, strings.TrimSpace(pair[1])); err != nil {
+			return err
+		}
+
+		result.SetMapIndex(key, elem)
+	}
+
+	field.Set(result)
+
+	return nil
+}
+
+func splitList(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return []string{}
+	}
+
+	items := strings.Split(value, listSeparator)
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+
+	return items
+}
@@ -0,0 +1,104 @@
+package v1
+
+import (
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testLevel string
+
+type testRichConfig struct {
+	RichTimeout  time.Duration
+	RichRatio    float64
+	RichOffset   int64
+	RichWorkers  uint
+	RichHosts    []string
+	RichPorts    []int
+	RichLabels   map[string]string
+	RichEndpoint *url.URL
+	RichStartAt  time.Time
+	RichIP       net.IP
+	RichLevel    testLevel
+	RichOptional *int
+}
+
+func TestDecode(t *testing.T) {
+	t.Setenv("RICH_TIMEOUT", "1m30s")
+	t.Setenv("RICH_RATIO", "0.75")
+	t.Setenv("RICH_OFFSET", "-9000000000")
+	t.Setenv("RICH_WORKERS", "8")
+	t.Setenv("RICH_HOSTS", "a.local, b.local")
+	t.Setenv("RICH_PORTS", "80,443")
+	t.Setenv("RICH_LABELS", "team=core, env=dev")
+	t.Setenv("RICH_ENDPOINT", "https://example.com/api")
+	t.Setenv("RICH_START_AT", "2023-08-01T10:00:00Z")
+	t.Setenv("RICH_IP", "10.0.0.1")
+	t.Setenv("RICH_LEVEL", "debug")
+	t.Setenv("RICH_OPTIONAL", "3")
+
+	err := RegisterDecoder(func(value string) (testLevel, error) {
+		if value != "debug" && value != "info" {
+			return "", errors.New("unknown level")
+		}
+
+		return testLevel(strings.ToUpper(value)), nil
+	})
+	assert.Equal(t, nil, err)
+
+	env, err := New[testRichConfig]()
+	assert.Equal(t, nil, err)
+
+	endpoint, _ := url.Parse("https://example.com/api")
+	optional := 3
+
+	assert.Equal(t, &testRichConfig{
+		RichTimeout:  90 * time.Second,
+		RichRatio:    0.75,
+		RichOffset:   -9000000000,
+		RichWorkers:  8,
+		RichHosts:    []string{"a.local", "b.local"},
+		RichPorts:    []int{80, 443},
+		RichLabels:   map[string]string{"team": "core", "env": "dev"},
+		RichEndpoint: endpoint,
+		RichStartAt:  time.Date(2023, 8, 1, 10, 0, 0, 0, time.UTC),
+		RichIP:       net.ParseIP("10.0.0.1"),
+		RichLevel:    "DEBUG",
+		RichOptional: &optional,
+	}, env.Get())
+
+	scenarios := []struct {
+		Name        string
+		Env         string
+		Value       string
+		ExpectedErr string
+	}{
+		{"duration", "RICH_TIMEOUT", "10", "config: RICH_TIMEOUT (time.Duration): could not parse found value to duration: value \"10\""},
+		{"float", "RICH_RATIO", "x", "config: RICH_RATIO (float64): could not parse found value to float: value \"x\""},
+		{"uint", "RICH_WORKERS", "-1", "config: RICH_WORKERS (uint): could not parse found value to unsigned integer: value \"-1\""},
+		{"slice", "RICH_PORTS", "80,http", "config: RICH_PORTS ([]int): could not parse found value to integer: value \"80,http\""},
+		{"map", "RICH_LABELS", "team", "config: RICH_LABELS (map[string]string): could not parse found value to map, expected \"key=value\" pairs: value \"team\""},
+		{"custom", "RICH_LEVEL", "trace", "config: RICH_LEVEL (v1.testLevel): unknown level: value \"trace\""},
+	}
+
+	for _, scenario := range scenarios {
+		previous := os.Getenv(scenario.Env)
+		t.Setenv(scenario.Env, scenario.Value)
+
+		_, err := New[testRichConfig]()
+		assert.Equal(t, scenario.ExpectedErr, err.Error(), scenario.Name)
+
+		t.Setenv(scenario.Env, previous)
+	}
+}
+
+func TestRegisterDecoder_Nil(t *testing.T) {
+	err := RegisterDecoder[testLevel](nil)
+	assert.Equal(t, "config: decoder cannot be nil", err.Error())
+}
@@ -2,135 +2,171 @@ package v1
 
 import (
 	"errors"
-	"fmt"
-	"log"
 	"os"
 	"reflect"
-	"strconv"
-	"strings"
-	"sync"
 
 	"github.com/ampliway/way-lib-go/config"
-	"github.com/iancoleman/strcase"
 )
 
 var (
 	_                      config.V1[any] = (*Env[any])(nil)
 	errGenericNotSupported                = errors.New("only structs are supported by config module")
-	errFieldNotSupported                  = errors.New("only \"string\", \"int\" and \"bool\" types are supported in struct")
+	errFieldNotSupported                  = errors.New("field type is not supported")
+	errFieldCycle                         = errors.New("field type refers back to its own struct")
 	errEnvNotFound                        = errors.New("environment variable not found")
 	errEnvIntParse                        = errors.New("could not parse found value to integer")
 	errEnvBoolParse                       = errors.New("could not parse found value to boolean")
 )
 
+const envNameSeparator = "_"
+
 type Env[T any] struct {
-	value *T
+	value  *T
+	fields []Field
 }
 
-var (
-	once sync.Once
-	args map[string]string
-)
-
 func New[T any]() (*Env[T], error) {
-	once.Do(func() {
-		args = extractArgs(os.Args, true)
-
-		file, exist := args["f"]
-		if exist {
-			data, err := os.ReadFile(file)
-			if err != nil {
-				log.Fatal(err)
+	return Load[T](NewLoader(os.Args[1:], os.LookupEnv, nil))
+}
+
+func (e *Env[T]) Get() *T {
+	return e.value
+}
+
+// Fields lists every resolved variable with its source, secrets masked.
+func (e *Env[T]) Fields() []Field {
+	result := make([]Field, 0, len(e.fields))
+	for _, field := range e.fields {
+		result = append(result, field.masked())
+	}
+
+	return result
+}
+
+// loadStruct loads every variable of v, adding each problem to the report so
+// a single run lists all of them.
+func (r *resolver) loadStruct(v reflect.Value, prefix string) {
+	for _, spec := range fieldSpecs(v.Type(), prefix) {
+		if spec.err != nil {
+			r.report.add(&FieldError{Name: spec.name, Type: spec.typ.String(), Err: spec.err})
+
+			continue
+		}
+
+		r.loadField(fieldByIndex(v, spec.index), spec.name, spec.tags)
+	}
+}
+
+// fieldByIndex returns the nested field, allocating nil struct pointers on the
+// way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
 			}
 
-			values := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
-			args = extractArgs(values, false)
+			v = v.Elem()
 		}
-	})
 
-	value := *new(T)
-	if reflect.ValueOf(value).Kind() != reflect.Struct {
-		return nil, fmt.Errorf("%s: %w", config.MODULE_NAME, errGenericNotSupported)
+		v = v.Field(x)
 	}
 
-	t := reflect.TypeOf(value)
-	v := reflect.ValueOf(&value).Elem()
+	return v
+}
 
-	overideValues := map[string]string{}
-	for i := 0; i < t.NumField(); i++ {
-		f := t.Field(i)
-		envName := strcase.ToScreamingSnake(f.Name)
+func (r *resolver) loadField(field reflect.Value, envName string, tags *fieldTags) {
+	fieldType := field.Type().String()
 
-		if value, exist := args[envName]; exist {
-			overideValues[envName] = value
-		}
+	resolved, err := r.resolve(envName)
+	if err != nil {
+		r.report.add(&FieldError{Name: envName, Type: fieldType, Rule: resolveRule(err), Err: err})
+
+		return
+	}
+
+	if resolved == nil && tags.hasDefault && r.literal {
+		resolved = &Field{Name: envName, Value: tags.defaultVal, Source: SourceDefault}
 	}
 
-	for i := 0; i < t.NumField(); i++ {
-		f := t.Field(i)
-		envName := strcase.ToScreamingSnake(f.Name)
+	if resolved == nil && tags.hasDefault {
+		value, err := r.interpolate(tags.defaultVal, []string{envName})
+		if err != nil {
+			r.report.add(&FieldError{Name: envName, Type: fieldType, Rule: tagDefault, Err: err})
 
-		envValue, envExist := overideValues[envName]
-		if !envExist {
-			envValue, envExist = os.LookupEnv(envName)
+			return
 		}
 
-		if !envExist {
-			return nil, fmt.Errorf("%s: %w: %s", config.MODULE_NAME, errEnvNotFound, envName)
+		resolved = &Field{Name: envName, Value: value, Source: SourceDefault}
+	}
+
+	if resolved == nil {
+		if tags.required {
+			r.report.add(&FieldError{Name: envName, Type: fieldType, Rule: ruleRequired, Err: errEnvNotFound})
 		}
 
-		field := v.FieldByName(f.Name)
+		r.fields = append(r.fields, Field{Name: envName, Type: fieldType, Source: SourceUnset, Secret: tags.isSecret(envName)})
 
-		switch f.Type.String() {
-		case "string":
-			field.SetString(envValue)
-		case "int":
-			envValueInt, err := strconv.Atoi(envValue)
-			if err != nil {
-				return nil, fmt.Errorf("%s: %w: %s: value \"%s\"", config.MODULE_NAME, errEnvIntParse, envName, envValue)
-			}
-			field.SetInt(int64(envValueInt))
-		case "bool":
-			envValueBool, err := strconv.ParseBool(envValue)
-			if err != nil {
-				return nil, fmt.Errorf("%s: %w: %s: value \"%s\"", config.MODULE_NAME, errEnvBoolParse, envName, envValue)
-			}
-			field.SetBool(envValueBool)
-		default:
-			return nil, fmt.Errorf("%s: %w", config.MODULE_NAME, errFieldNotSupported)
+		return
+	}
+
+	resolved.Type = fieldType
+	resolved.Secret = resolved.Secret || tags.isSecret(envName)
+	r.fields = append(r.fields, *resolved)
+
+	displayValue := resolved.masked().Value
+
+	if err := decode(field, resolved.Value); err != nil {
+		fieldErr := &FieldError{Name: envName, Type: fieldType, Value: displayValue, Err: err}
+		if errors.Is(err, errFieldNotSupported) {
+			fieldErr.Value = ""
 		}
+
+		r.report.add(fieldErr)
+
+		return
 	}
 
-	return &Env[T]{
-		value: &value,
-	}, nil
+	for _, rule := range tags.rules {
+		if err := checkRule(field, resolved.Value, rule); err != nil {
+			r.report.add(&FieldError{Name: envName, Type: fieldType, Rule: rule.String(), Value: displayValue, Err: err})
+		}
+	}
 }
 
-func (e *Env[T]) Get() *T {
-	return e.value
+func envFieldName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+
+	return prefix + envNameSeparator + name
 }
 
-func extractArgs(values []string, ignoreFirst bool) map[string]string {
-	result := map[string]string{}
+func isNestedStruct(t reflect.Type) bool {
+	if isDecodable(t) {
+		return false
+	}
 
-	for i, arg := range values {
-		if i == 0 && ignoreFirst {
-			continue
-		}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
 
-		removePrefix := 0
-		if strings.HasPrefix(arg, "-") {
-			removePrefix = 1
-		}
+	return t.Kind() == reflect.Struct && !isDecodable(t)
+}
 
-		if !strings.Contains(arg, "=") {
-			continue
-		}
+func resolveRule(err error) string {
+	if errors.Is(err, errSecretFileRead) || errors.Is(err, errSecretResolve) {
+		return ruleSecret
+	}
 
-		args := strings.SplitN(arg, "=", 2)
+	if errors.Is(err, errSourceLookup) {
+		return ruleSource
+	}
 
-		result[args[0][removePrefix:]] = args[1]
+	if errors.Is(err, errKeyFileNotSet) || errors.Is(err, errKeyRead) || errors.Is(err, errKeyInvalid) ||
+		errors.Is(err, errDecrypt) || errors.Is(err, errEncryptedInvalid) {
+		return ruleDecrypt
 	}
 
-	return result
+	return ruleInterpolate
 }
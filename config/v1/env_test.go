@@ -1,14 +1,14 @@
 package v1
 
 import (
-	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type testFieldNotSupportedConfig struct {
-	FieldX uint64
+	FieldX complex64
 }
 
 type testConfig struct {
@@ -17,6 +17,22 @@ type testConfig struct {
 	Field3 bool
 }
 
+type testNestedDatabase struct {
+	Host string
+	Port int
+}
+
+type testNestedConfig struct {
+	testEmbeddedConfig
+	Database testNestedDatabase
+	Replica  *testNestedDatabase
+	internal string
+}
+
+type testEmbeddedConfig struct {
+	AppName string
+}
+
 func TestNew(t *testing.T) {
 	scenarios := []struct {
 		Name        string
@@ -38,64 +54,78 @@ func TestNew(t *testing.T) {
 				return New[testConfig]()
 			},
 			Expected:    (*Env[testConfig])(nil),
-			ExpectedErr: "config: environment variable not found: FIELD_1",
+			ExpectedErr: "config: 3 invalid variables: FIELD_1 (string, required): environment variable not found; FIELD_2 (int, required): environment variable not found; FIELD_3 (bool, required): environment variable not found",
 		},
 		{
 			Name: "field_not_supported",
 			Test: func() (any, error) {
-				os.Setenv("FIELD_X", "1")
+				t.Setenv("FIELD_X", "1")
 
 				return New[testFieldNotSupportedConfig]()
 			},
 			Expected:    (*Env[testFieldNotSupportedConfig])(nil),
-			ExpectedErr: "config: only \"string\", \"int\" and \"bool\" types are supported in struct",
+			ExpectedErr: "config: FIELD_X (complex64): field type is not supported",
 		},
 		{
 			Name: "field_int_parse_failed",
 			Test: func() (any, error) {
-				os.Setenv("FIELD_1", "a")
-				os.Setenv("FIELD_2", "b")
-				os.Setenv("FIELD_3", "c")
+				t.Setenv("FIELD_1", "a")
+				t.Setenv("FIELD_2", "b")
+				t.Setenv("FIELD_3", "c")
 
 				return New[testConfig]()
 			},
 			Expected:    (*Env[testConfig])(nil),
-			ExpectedErr: "config: could not parse found value to integer: FIELD_2: value \"b\"",
+			ExpectedErr: "config: 2 invalid variables: FIELD_2 (int): could not parse found value to integer: value \"b\"; FIELD_3 (bool): could not parse found value to boolean: value \"c\"",
 		},
 		{
 			Name: "field_bool_parse_failed",
 			Test: func() (any, error) {
-				os.Setenv("FIELD_1", "a")
-				os.Setenv("FIELD_2", "1")
-				os.Setenv("FIELD_3", "c")
+				t.Setenv("FIELD_1", "a")
+				t.Setenv("FIELD_2", "1")
+				t.Setenv("FIELD_3", "c")
 
 				return New[testConfig]()
 			},
 			Expected:    (*Env[testConfig])(nil),
-			ExpectedErr: "config: could not parse found value to boolean: FIELD_3: value \"c\"",
+			ExpectedErr: "config: FIELD_3 (bool): could not parse found value to boolean: value \"c\"",
 		},
 		{
 			Name: "success_1",
 			Test: func() (any, error) {
-				os.Setenv("FIELD_1", "a")
-				os.Setenv("FIELD_2", "1")
-				os.Setenv("FIELD_3", "true")
+				t.Setenv("FIELD_1", "a")
+				t.Setenv("FIELD_2", "1")
+				t.Setenv("FIELD_3", "true")
 
 				return New[testConfig]()
 			},
-			Expected:    &Env[testConfig]{value: &testConfig{Field1: "a", Field2: 1, Field3: true}},
+			Expected: &Env[testConfig]{
+				value: &testConfig{Field1: "a", Field2: 1, Field3: true},
+				fields: []Field{
+					{Name: "FIELD_1", Type: "string", Source: SourceEnv, Value: "a"},
+					{Name: "FIELD_2", Type: "int", Source: SourceEnv, Value: "1"},
+					{Name: "FIELD_3", Type: "bool", Source: SourceEnv, Value: "true"},
+				},
+			},
 			ExpectedErr: "",
 		},
 		{
 			Name: "success_2",
 			Test: func() (any, error) {
-				os.Setenv("FIELD_1", "b")
-				os.Setenv("FIELD_2", "-1")
-				os.Setenv("FIELD_3", "false")
+				t.Setenv("FIELD_1", "b")
+				t.Setenv("FIELD_2", "-1")
+				t.Setenv("FIELD_3", "false")
 
 				return New[testConfig]()
 			},
-			Expected:    &Env[testConfig]{value: &testConfig{Field1: "b", Field2: -1, Field3: false}},
+			Expected: &Env[testConfig]{
+				value: &testConfig{Field1: "b", Field2: -1, Field3: false},
+				fields: []Field{
+					{Name: "FIELD_1", Type: "string", Source: SourceEnv, Value: "b"},
+					{Name: "FIELD_2", Type: "int", Source: SourceEnv, Value: "-1"},
+					{Name: "FIELD_3", Type: "bool", Source: SourceEnv, Value: "false"},
+				},
+			},
 			ExpectedErr: "",
 		},
 	}
@@ -116,9 +146,9 @@ func TestNew(t *testing.T) {
 }
 
 func TestGet(t *testing.T) {
-	os.Setenv("FIELD_1", "b")
-	os.Setenv("FIELD_2", "-1")
-	os.Setenv("FIELD_3", "false")
+	t.Setenv("FIELD_1", "b")
+	t.Setenv("FIELD_2", "-1")
+	t.Setenv("FIELD_3", "false")
 
 	env, err := New[testConfig]()
 	assert.Equal(t, nil, err)
@@ -131,3 +161,45 @@ func TestGet(t *testing.T) {
 		Field3: false,
 	}, value)
 }
+
+func TestNew_Nested(t *testing.T) {
+	t.Setenv("APP_NAME", "app")
+	t.Setenv("DATABASE_HOST", "primary")
+	t.Setenv("DATABASE_PORT", "5432")
+	t.Setenv("REPLICA_HOST", "replica")
+
+	_, err := New[testNestedConfig]()
+	require.Error(t, err)
+	assert.Equal(t, "config: REPLICA_PORT (int, required): environment variable not found", err.Error())
+
+	t.Setenv("REPLICA_PORT", "5433")
+
+	env, err := New[testNestedConfig]()
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, &testNestedConfig{
+		testEmbeddedConfig: testEmbeddedConfig{AppName: "app"},
+		Database:           testNestedDatabase{Host: "primary", Port: 5432},
+		Replica:            &testNestedDatabase{Host: "replica", Port: 5433},
+	}, env.Get())
+}
+
+type testCycleNode struct {
+	Name string `required:"false"`
+	Next *testCycleNode
+}
+
+type testCycleConfig struct {
+	Head  testCycleNode
+	Other testNestedDatabase
+}
+
+func TestLoad_Cycle(t *testing.T) {
+	t.Parallel()
+
+	_, err := Load[testCycleConfig](NewLoader([]string{"-OTHER_HOST=a", "-OTHER_PORT=1"}, nil, nil))
+	assert.Equal(t, "config: HEAD_NEXT (*v1.testCycleNode): field type refers back to its own struct", err.Error())
+
+	_, err = Describe[testCycleNode]()
+	assert.Equal(t, "config: NEXT (*v1.testCycleNode): field type refers back to its own struct", err.Error())
+}
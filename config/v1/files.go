@@ -0,0 +1,217 @@
+package v1
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/iancoleman/strcase"
+	"gopkg.in/yaml.v3"
+)
+
+const fileArg = "f"
+
+var (
+	errFileRead        = errors.New("could not read config file")
+	errFileParse       = errors.New("could not parse config file")
+	errTOMLArrayTables = errors.New("arrays of tables are not supported")
+)
+
+// parseFile turns a config file into flat variable names. The format is picked
+// by extension: YAML, JSON and TOML documents are flattened (database.host ->
+// DATABASE_HOST), anything else is read as dotenv.
+func parseFile(path string, data []byte) (map[string]string, error) {
+	var (
+		doc map[string]any
+		err error
+	)
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &doc)
+	case ".json":
+		err = json.Unmarshal(data, &doc)
+	case ".toml":
+		doc, err = parseTOML(data)
+	default:
+		return parseDotenv(string(data))
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]string{}
+	flatten(result, "", doc)
+
+	return result, nil
+}
+
+func flatten(result map[string]string, prefix string, doc map[string]any) {
+	for key, value := range doc {
+		name := envFieldName(prefix, strcase.ToScreamingSnake(key))
+
+		switch v := value.(type) {
+		case map[string]any:
+			flatten(result, name, v)
+
+			if pairs, ok := scalarPairs(v); ok {
+				result[name] = pairs
+			}
+		default:
+			result[name] = scalar(value)
+		}
+	}
+}
+
+// scalarPairs renders a mapping of scalars as "key=value" pairs so it can also
+// feed a map field.
+func scalarPairs(doc map[string]any) (string, bool) {
+	keys := make([]string, 0, len(doc))
+	for key, value := range doc {
+		switch value.(type) {
+		case map[string]any, []any:
+			return "", false
+		}
+
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+keyValueSeparator+scalar(doc[key]))
+	}
+
+	return strings.Join(pairs, listSeparator), true
+}
+
+func scalar(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, scalar(item))
+		}
+
+		return strings.Join(items, listSeparator)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func parseDotenv(data string) (map[string]string, error) {
+	result := map[string]string{}
+
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		text = strings.TrimSpace(strings.TrimPrefix(text, "export "))
+
+		pair := strings.SplitN(text, "=", 2)
+		if len(pair) != 2 {
+			return nil, fmt.Errorf("line %d: expected \"KEY=VALUE\"", line)
+		}
+
+		value, err := dotenvValue(strings.TrimSpace(pair[1]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		result[strings.TrimSpace(pair[0])] = value
+	}
+
+	return result, scanner.Err()
+}
+
+func dotenvValue(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+
+	switch raw[0] {
+	case '"':
+		end := closingQuote(raw, '"')
+		if end < 0 {
+			return "", errors.New("unterminated double quote")
+		}
+
+		return strconv.Unquote(raw[:end+1])
+	case '\'':
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", errors.New("unterminated single quote")
+		}
+
+		return raw[1 : end+1], nil
+	}
+
+	if i := strings.Index(raw, " #"); i >= 0 {
+		raw = raw[:i]
+	}
+
+	return strings.TrimSpace(raw), nil
+}
+
+func closingQuote(raw string, quote byte) int {
+	for i := 1; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++
+		case quote:
+			return i
+		}
+	}
+
+	return -1
+}
+
+// parseTOML decodes a TOML document. Arrays of tables have no flat variable
+// names, so they are rejected.
+func parseTOML(data []byte) (map[string]any, error) {
+	doc := map[string]any{}
+	if err := toml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+
+	if err := checkTOMLTables(doc, ""); err != nil {
+		return nil, err
+	}
+
+	return doc, nil
+}
+
+func checkTOMLTables(doc map[string]any, prefix string) error {
+	for key, value := range doc {
+		switch v := value.(type) {
+		case []map[string]any:
+			return fmt.Errorf("%w: %s", errTOMLArrayTables, strings.TrimPrefix(prefix+"."+key, "."))
+		case map[string]any:
+			if err := checkTOMLTables(v, prefix+"."+key); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package v1
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFile(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		Name     string
+		Path     string
+		Data     string
+		Expected map[string]string
+	}{
+		{
+			Name: "dotenv",
+			Path: "config.env",
+			Data: "# comment\n" +
+				"export FIELD_1=a\n" +
+				"FIELD_2 = 2 # inline\n" +
+				"FIELD_3=\"quoted # value\\n\"\n" +
+				"FIELD_4='single \"quoted\"'\n" +
+				"\n",
+			Expected: map[string]string{
+				"FIELD_1": "a",
+				"FIELD_2": "2",
+				"FIELD_3": "quoted # value\n",
+				"FIELD_4": "single \"quoted\"",
+			},
+		},
+		{
+			Name: "yaml",
+			Path: "config.yaml",
+			Data: "field1: a\n" +
+				"database:\n" +
+				"  host: db\n" +
+				"  port: 5432\n" +
+				"hosts: [a, b]\n",
+			Expected: map[string]string{
+				"FIELD_1":       "a",
+				"DATABASE":      "host=db,port=5432",
+				"DATABASE_HOST": "db",
+				"DATABASE_PORT": "5432",
+				"HOSTS":         "a,b",
+			},
+		},
+		{
+			Name: "json",
+			Path: "config.JSON",
+			Data: `{"FIELD_1": "a", "ratio": 0.5, "port": 8080, "secure": true, "empty": null}`,
+			Expected: map[string]string{
+				"FIELD_1": "a",
+				"RATIO":   "0.5",
+				"PORT":    "8080",
+				"SECURE":  "true",
+				"EMPTY":   "",
+			},
+		},
+		{
+			Name: "toml",
+			Path: "config.toml",
+			Data: "field1 = \"a # not a comment\" # comment\n" +
+				"port = 8_080\n" +
+				"user_name = \"my_user\"\n" +
+				"started = 2023-08-01T10:00:00Z\n" +
+				"motd = \"\"\"\nhello\nworld\"\"\"\n" +
+				"limits = { rows = 10, ratio = 0.5 }\n" +
+				"[database]\n" +
+				"host = 'db'\n" +
+				"replica.hosts = [\n  \"r1\",\n  \"r2\",\n]\n",
+			Expected: map[string]string{
+				"FIELD_1":                "a # not a comment",
+				"PORT":                   "8080",
+				"USER_NAME":              "my_user",
+				"STARTED":                "2023-08-01T10:00:00Z",
+				"MOTD":                   "hello\nworld",
+				"LIMITS":                 "ratio=0.5,rows=10",
+				"LIMITS_ROWS":            "10",
+				"LIMITS_RATIO":           "0.5",
+				"DATABASE_HOST":          "db",
+				"DATABASE_REPLICA_HOSTS": "r1,r2",
+			},
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.Name, func(t *testing.T) {
+			t.Parallel()
+
+			actual, err := parseFile(scenario.Path, []byte(scenario.Data))
+			assert.Equal(t, nil, err)
+
+			assert.Equal(t, scenario.Expected, actual)
+		})
+	}
+}
+
+func TestParseFile_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := parseFile("config.env", []byte("FIELD_1"))
+	assert.Equal(t, "line 1: expected \"KEY=VALUE\"", err.Error())
+
+	_, err = parseFile("config.env", []byte("FIELD_1=\"open"))
+	assert.Equal(t, "line 1: unterminated double quote", err.Error())
+
+	_, err = parseFile("config.toml", []byte("[cluster]\n[[cluster.servers]]\nhost = 'a'\n"))
+	assert.Equal(t, "arrays of tables are not supported: cluster.servers", err.Error())
+
+	_, err = parseFile("config.toml", []byte("name = \"open\n"))
+	assert.NotNil(t, err)
+
+	_, err = parseFile("config.json", []byte("{"))
+	assert.NotNil(t, err)
+}
+
+func TestReadFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	override := filepath.Join(dir, "override.env")
+
+	assert.Equal(t, nil, os.WriteFile(base, []byte("field1: base\nfield2: 1\n"), 0o600))
+	assert.Equal(t, nil, os.WriteFile(override, []byte("FIELD_2=2\n"), 0o600))
+
+	actual, err := readFiles(os.ReadFile, []string{base, override})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, map[string]fileValue{
+		"FIELD_1": {value: "base", path: base},
+		"FIELD_2": {value: "2", path: override},
+	}, actual)
+
+	_, err = readFiles(os.ReadFile, []string{filepath.Join(dir, "missing.env")})
+	assert.ErrorIs(t, err, errFileRead)
+}
+
+func TestExtractFiles(t *testing.T) {
+	t.Parallel()
+
+	actual := extractFiles([]string{"-f=a.yaml", "-FIELD_1=x", "-f=b.env", "-f="})
+	assert.Equal(t, []string{"a.yaml", "b.env"}, actual)
+}
+
+func TestResolverLookup(t *testing.T) {
+	t.Parallel()
+
+	r := &resolver{
+		args: map[string]string{"A": "a-args"},
+		env: func(key string) (string, bool) {
+			value, exist := map[string]string{"A": "a-env", "B": "b-env"}[key]
+
+			return value, exist
+		},
+		files: map[string]fileValue{
+			"A": {value: "a-file", path: "a.yaml"},
+			"B": {value: "b-file", path: "a.yaml"},
+			"C": {value: "c-file", path: "c.yaml"},
+		},
+	}
+
+	scenarios := map[string]Field{
+		"A": {Name: "A", Value: "a-args", Source: SourceArgs},
+		"B": {Name: "B", Value: "b-env", Source: SourceEnv},
+		"C": {Name: "C", Value: "c-file", Source: SourceFile, Origin: "c.yaml"},
+	}
+
+	for key, expected := range scenarios {
+		actual, exist, err := r.lookup(key)
+		assert.NoError(t, err)
+		assert.True(t, exist)
+		assert.Equal(t, expected, *actual)
+	}
+
+	_, exist, err := r.lookup("D")
+	assert.NoError(t, err)
+	assert.False(t, exist)
+}
@@ -0,0 +1,118 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	interpolateOpen    = "${"
+	interpolateClose   = '}'
+	interpolateDefault = ":-"
+	ruleInterpolate    = "interpolate"
+)
+
+var (
+	errInterpolateUndefined = errors.New("undefined variable in interpolation")
+	errInterpolateCycle     = errors.New("cycle in interpolation")
+	errInterpolateSyntax    = errors.New("unterminated interpolation")
+)
+
+// interpolateField expands the value of field when it comes from a config
+// file. Args, environment and remote values are kept literal: a secret set
+// there may well contain "${".
+func (r *resolver) interpolateField(field *Field, stack []string) (string, error) {
+	if field.Source != SourceFile {
+		return field.Value, nil
+	}
+
+	return r.interpolate(field.Value, stack)
+}
+
+// interpolate expands ${VAR} and ${VAR:-default} references in value, taken
+// from a default or a config file, through the resolver layers. stack holds
+// the variables being expanded to detect cycles; "$${" is kept as a literal
+// "${".
+func (r *resolver) interpolate(value string, stack []string) (string, error) {
+	if !strings.Contains(value, interpolateOpen) {
+		return value, nil
+	}
+
+	var b strings.Builder
+
+	for {
+		start := strings.Index(value, interpolateOpen)
+		if start < 0 {
+			b.WriteString(value)
+
+			return b.String(), nil
+		}
+
+		if start > 0 && value[start-1] == '$' {
+			b.WriteString(value[:start-1])
+			b.WriteString(interpolateOpen)
+			value = value[start+len(interpolateOpen):]
+
+			continue
+		}
+
+		end := closingBrace(value, start+len(interpolateOpen))
+		if end < 0 {
+			return "", fmt.Errorf("%w: %s", errInterpolateSyntax, value[start:])
+		}
+
+		expanded, err := r.expand(value[start+len(interpolateOpen):end], stack)
+		if err != nil {
+			return "", err
+		}
+
+		b.WriteString(value[:start])
+		b.WriteString(expanded)
+		value = value[end+1:]
+	}
+}
+
+func (r *resolver) expand(reference string, stack []string) (string, error) {
+	name, fallback, hasFallback := strings.Cut(reference, interpolateDefault)
+	name = strings.TrimSpace(name)
+
+	for i, item := range stack {
+		if item == name {
+			return "", fmt.Errorf("%w: %s", errInterpolateCycle, strings.Join(append(stack[i:], name), " -> "))
+		}
+	}
+
+	field, exist, err := r.lookup(name)
+	if err != nil {
+		return "", err
+	}
+
+	if !exist || (hasFallback && field.Value == "") {
+		if !hasFallback {
+			return "", fmt.Errorf("%w: %s", errInterpolateUndefined, name)
+		}
+
+		return r.interpolate(fallback, stack)
+	}
+
+	return r.interpolateField(field, append(stack, name))
+}
+
+func closingBrace(value string, from int) int {
+	depth := 0
+
+	for i := from; i < len(value); i++ {
+		switch {
+		case strings.HasPrefix(value[i:], interpolateOpen):
+			depth++
+			i++
+		case value[i] == interpolateClose && depth == 0:
+			return i
+		case value[i] == interpolateClose:
+			depth--
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,155 @@
+package v1
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testInterpolateConfig struct {
+	Host     string
+	Endpoint string
+	Backup   string `default:"${HOST}:9000"`
+	Fallback string `default:"${MISSING:-${HOST}}"`
+	Literal  string `default:"$${HOST}"`
+}
+
+func TestInterpolate(t *testing.T) {
+	t.Parallel()
+
+	scenarios := []struct {
+		Name        string
+		File        map[string]string
+		Env         map[string]string
+		Expected    *testInterpolateConfig
+		ExpectedErr string
+	}{
+		{
+			Name: "success",
+			File: map[string]string{
+				"HOST":     "db.local",
+				"ENDPOINT": "https://${HOST}:${PORT:-8443}/api",
+			},
+			Expected: &testInterpolateConfig{
+				Host:     "db.local",
+				Endpoint: "https://db.local:8443/api",
+				Backup:   "db.local:9000",
+				Fallback: "db.local",
+				Literal:  "${HOST}",
+			},
+		},
+		{
+			Name: "chained",
+			File: map[string]string{
+				"DOMAIN":   "example.com",
+				"HOST":     "api.${DOMAIN}",
+				"ENDPOINT": "https://${HOST}",
+			},
+			Expected: &testInterpolateConfig{
+				Host:     "api.example.com",
+				Endpoint: "https://api.example.com",
+				Backup:   "api.example.com:9000",
+				Fallback: "api.example.com",
+				Literal:  "${HOST}",
+			},
+		},
+		{
+			Name: "undefined",
+			File: map[string]string{
+				"HOST":     "db.local",
+				"ENDPOINT": "https://${DOMAIN}",
+			},
+			ExpectedErr: "config: ENDPOINT (string, interpolate): undefined variable in interpolation: DOMAIN",
+		},
+		{
+			Name: "cycle",
+			File: map[string]string{
+				"HOST":     "${ENDPOINT}",
+				"ENDPOINT": "${HOST}",
+			},
+			ExpectedErr: "config: 4 invalid variables: " +
+				"HOST (string, interpolate): cycle in interpolation: HOST -> ENDPOINT -> HOST; " +
+				"ENDPOINT (string, interpolate): cycle in interpolation: ENDPOINT -> HOST -> ENDPOINT; " +
+				"BACKUP (string, default): cycle in interpolation: HOST -> ENDPOINT -> HOST; " +
+				"FALLBACK (string, default): cycle in interpolation: HOST -> ENDPOINT -> HOST",
+		},
+		{
+			Name: "env literal",
+			File: map[string]string{
+				"ENDPOINT": "https://${HOST}/api",
+			},
+			Env: map[string]string{
+				"HOST":     "p@ss${word",
+				"FALLBACK": "${HOST}",
+			},
+			Expected: &testInterpolateConfig{
+				Host:     "p@ss${word",
+				Endpoint: "https://p@ss${word/api",
+				Backup:   "p@ss${word:9000",
+				Fallback: "${HOST}",
+				Literal:  "${HOST}",
+			},
+		},
+		{
+			Name: "unterminated",
+			File: map[string]string{
+				"HOST":     "db.local",
+				"ENDPOINT": "https://${HOST",
+			},
+			ExpectedErr: "config: ENDPOINT (string, interpolate): unterminated interpolation: ${HOST",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.Name, func(t *testing.T) {
+			t.Parallel()
+
+			lines := []string{}
+			for key, value := range scenario.File {
+				lines = append(lines, key+"="+value)
+			}
+
+			fsys := fstest.MapFS{"config.env": {Data: []byte(strings.Join(lines, "\n"))}}
+			loader := NewLoader([]string{"-f=config.env"}, LookupMap(scenario.Env), fsys)
+
+			env, err := Load[testInterpolateConfig](loader)
+			if scenario.ExpectedErr != "" {
+				assert.Equal(t, scenario.ExpectedErr, err.Error())
+
+				return
+			}
+
+			assert.Equal(t, nil, err)
+			assert.Equal(t, scenario.Expected, env.Get())
+		})
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"conf/config.yaml":        {Data: []byte("field1: base\nfield2: 1\nfield3: false\n")},
+		"conf/config.staging.env": {Data: []byte("FIELD_2=2\n")},
+		"conf/config.eu.yaml":     {Data: []byte("field2: 3\nfield3: true\n")},
+		"conf/config.prod.yaml":   {Data: []byte("field1: prod\n")},
+		"conf/other.staging.yaml": {Data: []byte("field1: other\n")},
+	}
+
+	loader := NewLoader([]string{"-f=conf/config.yaml"}, LookupMap(map[string]string{"APP_PROFILE": "staging,eu"}), fsys)
+	assert.Equal(t, []string{"conf/config.yaml", "conf/config.staging.env", "conf/config.eu.yaml"}, loader.Files())
+
+	env, err := Load[testConfig](loader)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &testConfig{Field1: "base", Field2: 3, Field3: true}, env.Get())
+
+	loader = NewLoader([]string{"-f=conf/config.yaml", "-APP_PROFILE=prod"}, nil, fsys)
+	assert.Equal(t, []string{"conf/config.yaml", "conf/config.prod.yaml"}, loader.Files())
+
+	loader = NewLoader([]string{"-f=conf/config.yaml"}, nil, fsys)
+	assert.Equal(t, []string{"conf/config.yaml"}, loader.Files())
+}
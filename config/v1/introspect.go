@@ -0,0 +1,155 @@
+package v1
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ampliway/way-lib-go/config"
+)
+
+const (
+	SourceArgs    = "args"
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceRemote  = "remote"
+	SourceDefault = "default"
+	SourceUnset   = "unset"
+
+	tagSecret  = "secret"
+	secretMask = "******"
+)
+
+var secretNameParts = []string{"PASSWORD", "SECRET", "TOKEN", "PRIVATE_KEY", "API_KEY", "CREDENTIAL"}
+
+// Field is the resolved state of one variable: where its value came from and
+// the value itself, masked when the field holds a secret.
+type Field struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Source string `json:"source"`
+	Origin string `json:"origin,omitempty"`
+	Value  string `json:"value"`
+	Secret bool   `json:"secret"`
+}
+
+// IsSecretName reports whether a variable name looks like it holds a secret.
+func IsSecretName(name string) bool {
+	name = strings.ToUpper(name)
+
+	for _, part := range secretNameParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f Field) masked() Field {
+	if f.Secret && f.Value != "" {
+		f.Value = secretMask
+	}
+
+	return f
+}
+
+// Values lists the variables of the struct pointed by v with their current
+// values, secrets masked, to report a struct already loaded. Nil pointers are
+// reported with an empty value.
+func Values(v any) ([]Field, error) {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s: %w", config.MODULE_NAME, errGenericNotSupported)
+	}
+
+	result := []Field{}
+
+	for _, spec := range fieldSpecs(value.Elem().Type(), "") {
+		if spec.err != nil {
+			continue
+		}
+
+		field := Field{Name: spec.name, Type: spec.typ.String(), Secret: spec.tags.isSecret(spec.name)}
+		if fieldValue, exist := valueByIndex(value.Elem(), spec.index); exist {
+			field.Value = FormatValue(fieldValue)
+		}
+
+		result = append(result, field.masked())
+	}
+
+	return result, nil
+}
+
+// valueByIndex is fieldByIndex for reading: a nil pointer on the way means
+// the field has no value.
+func valueByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+
+			v = v.Elem()
+		}
+
+		v = v.Field(x)
+	}
+
+	return v, true
+}
+
+// FormatValue renders v the way decode reads it back: lists joined by ",",
+// maps as sorted "k=v" pairs and times as RFC 3339. Nil pointers, zero times
+// and unexported values are rendered empty.
+func FormatValue(v reflect.Value) string {
+	if !v.IsValid() || !v.CanInterface() {
+		return ""
+	}
+
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return ""
+	}
+
+	switch value := v.Interface().(type) {
+	case time.Time:
+		if value.IsZero() {
+			return ""
+		}
+
+		return value.Format(time.RFC3339)
+	case error:
+		return value.Error()
+	case fmt.Stringer:
+		return value.String()
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return FormatValue(v.Elem())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, FormatValue(v.Index(i)))
+		}
+
+		return strings.Join(items, listSeparator)
+	case reflect.Map:
+		items := make([]string, 0, v.Len())
+		for _, key := range v.MapKeys() {
+			items = append(items, FormatValue(key)+keyValueSeparator+FormatValue(v.MapIndex(key)))
+		}
+
+		sort.Strings(items)
+
+		return strings.Join(items, listSeparator)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,98 @@
+package v1
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testIntrospectConfig struct {
+	InspectHost     string
+	InspectPort     int    `default:"8080"`
+	InspectOptional string `required:"false"`
+	InspectPassword string
+	InspectCert     string `secret:"true"`
+	InspectKey      string
+	InspectToken    string `secret:"false"`
+}
+
+func TestFields(t *testing.T) {
+	keyFile := filepath.Join(t.TempDir(), "key")
+	assert.Equal(t, nil, os.WriteFile(keyFile, []byte("private\n"), 0o600))
+
+	t.Setenv("INSPECT_HOST", "localhost")
+	t.Setenv("INSPECT_PASSWORD", "p@ss")
+	t.Setenv("INSPECT_CERT", "cert")
+	t.Setenv("INSPECT_KEY_FILE", keyFile)
+	t.Setenv("INSPECT_TOKEN", "public")
+
+	env, err := New[testIntrospectConfig]()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "private", env.Get().InspectKey)
+
+	assert.Equal(t, []Field{
+		{Name: "INSPECT_HOST", Type: "string", Source: SourceEnv, Value: "localhost"},
+		{Name: "INSPECT_PORT", Type: "int", Source: SourceDefault, Value: "8080"},
+		{Name: "INSPECT_OPTIONAL", Type: "string", Source: SourceUnset},
+		{Name: "INSPECT_PASSWORD", Type: "string", Source: SourceEnv, Value: secretMask, Secret: true},
+		{Name: "INSPECT_CERT", Type: "string", Source: SourceEnv, Value: secretMask, Secret: true},
+		{Name: "INSPECT_KEY", Type: "string", Source: SourceEnv, Origin: "INSPECT_KEY_FILE", Value: secretMask, Secret: true},
+		{Name: "INSPECT_TOKEN", Type: "string", Source: SourceEnv, Value: "public"},
+	}, env.Fields())
+}
+
+func TestIsSecretName(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsSecretName("CACHE_PASSWORD"))
+	assert.True(t, IsSecretName("StorageSecretAccessKey"))
+	assert.True(t, IsSecretName("github_token"))
+	assert.False(t, IsSecretName("KAFKA_SERVERS"))
+	assert.False(t, IsSecretName("KAFKA_KEY_FILE"))
+}
+
+func TestValues(t *testing.T) {
+	t.Parallel()
+
+	type database struct {
+		Host string
+	}
+
+	type values struct {
+		Timeout  time.Duration
+		Endpoint *url.URL
+		Hosts    []string
+		Labels   map[string]string
+		Token    string
+		Database *database
+		Ratio    float64
+	}
+
+	endpoint, _ := url.Parse("https://example.com/api")
+
+	actual, err := Values(&values{
+		Timeout:  2 * time.Second,
+		Endpoint: endpoint,
+		Hosts:    []string{"a", "b"},
+		Labels:   map[string]string{"team": "core", "env": "prod"},
+		Token:    "t0k3n",
+		Ratio:    0.5,
+	})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, []Field{
+		{Name: "TIMEOUT", Type: "time.Duration", Value: "2s"},
+		{Name: "ENDPOINT", Type: "*url.URL", Value: "https://example.com/api"},
+		{Name: "HOSTS", Type: "[]string", Value: "a,b"},
+		{Name: "LABELS", Type: "map[string]string", Value: "env=prod,team=core"},
+		{Name: "TOKEN", Type: "string", Value: secretMask, Secret: true},
+		{Name: "DATABASE_HOST", Type: "string"},
+		{Name: "RATIO", Type: "float64", Value: "0.5"},
+	}, actual)
+
+	_, err = Values(values{})
+	assert.Equal(t, "config: only structs are supported by config module", err.Error())
+}
@@ -0,0 +1,315 @@
+package v1
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/ampliway/way-lib-go/config"
+)
+
+const profileVar = "APP_PROFILE"
+
+var (
+	_ fs.ReadFileFS = osFS{}
+
+	profileExtensions = []string{".yaml", ".yml", ".json", ".toml", ".env"}
+)
+
+// Loader resolves config structs from explicit sources: CLI style arguments
+// ("-KEY=value", "-f=file"), an environment lookup and the file system used for
+// -f and _FILE paths. It keeps no global state, so several loaders can coexist.
+type Loader struct {
+	args    map[string]string
+	files   []string
+	lookup  func(key string) (string, bool)
+	sources []config.Source
+	fsys    fs.FS
+}
+
+// NewLoader builds a Loader; args must not include the program name. A nil
+// lookup means no environment and a nil fsys reads from the OS file system.
+func NewLoader(args []string, lookup func(key string) (string, bool), fsys fs.FS) *Loader {
+	if lookup == nil {
+		lookup = LookupMap(nil)
+	}
+
+	if fsys == nil {
+		fsys = osFS{}
+	}
+
+	l := &Loader{
+		args:   extractArgs(args),
+		lookup: lookup,
+		fsys:   fsys,
+	}
+	l.files = l.profileFiles(extractFiles(args))
+
+	return l
+}
+
+// LookupMap adapts a map to the environment lookup used by NewLoader.
+func LookupMap(values map[string]string) func(key string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, exist := values[key]
+
+		return value, exist
+	}
+}
+
+func Load[T any](l *Loader) (*Env[T], error) {
+	value, fields, err := load[T](l)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Env[T]{
+		value:  value,
+		fields: fields,
+	}, nil
+}
+
+// AddSource layers a remote source over the -f files; the environment and CLI
+// args still take precedence. Sources added later win over earlier ones.
+func (l *Loader) AddSource(source config.Source) error {
+	if source == nil {
+		return fmt.Errorf("%s: %w", config.MODULE_NAME, errSourceNil)
+	}
+
+	l.sources = append(l.sources, source)
+
+	return nil
+}
+
+// LoadInto loads the struct pointed by target, for types only known at run
+// time.
+func (l *Loader) LoadInto(target any) ([]Field, error) {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, fmt.Errorf("%s: %w", config.MODULE_NAME, errGenericNotSupported)
+	}
+
+	return l.loadInto(v.Elem())
+}
+
+// Decode fills the struct pointed by target from values, keyed by variable
+// name, with the decoders, defaults and validation rules only. Values are
+// taken literally: no interpolation, secret files, secret resolvers nor
+// decryption, which suits values typed by hand like command flags.
+func Decode(target any, values map[string]string) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%s: %w", config.MODULE_NAME, errGenericNotSupported)
+	}
+
+	r := &resolver{args: values, env: LookupMap(nil), literal: true, report: Errors{}}
+
+	_, err := r.fill(v.Elem())
+
+	return err
+}
+
+func (l *Loader) Files() []string {
+	return append([]string{}, l.files...)
+}
+
+func load[T any](l *Loader) (*T, []Field, error) {
+	value := new(T)
+
+	fields, err := l.loadInto(reflect.ValueOf(value).Elem())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return value, fields, nil
+}
+
+func (l *Loader) loadInto(v reflect.Value) ([]Field, error) {
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s: %w", config.MODULE_NAME, errGenericNotSupported)
+	}
+
+	fileValues, err := readFiles(l.readFile, l.files)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &resolver{
+		args:     l.args,
+		env:      l.lookup,
+		sources:  l.sources,
+		files:    fileValues,
+		readFile: l.readFile,
+		report:   Errors{},
+	}
+	r.key = func() ([]byte, error) {
+		return l.key(r)
+	}
+
+	return r.fill(v)
+}
+
+// fill loads the struct v, then runs its Validate method.
+func (r *resolver) fill(v reflect.Value) ([]Field, error) {
+	r.loadStruct(v, "")
+
+	if err := r.report.orNil(); err != nil {
+		return nil, err
+	}
+
+	if err := runValidate(v.Addr().Interface()); err != nil {
+		return nil, fmt.Errorf("%s: %w", config.MODULE_NAME, err)
+	}
+
+	return r.fields, nil
+}
+
+// Key reads the encryption key pointed by CONFIG_KEY_FILE, looked up in the
+// loader args, environment and -f files.
+func (l *Loader) Key() ([]byte, error) {
+	fileValues, err := readFiles(l.readFile, l.files)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := l.key(&resolver{args: l.args, env: l.lookup, sources: l.sources, files: fileValues})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", config.MODULE_NAME, err)
+	}
+
+	return key, nil
+}
+
+func (l *Loader) key(r *resolver) ([]byte, error) {
+	keyFile, exist, err := r.lookup(KeyFileVar)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exist || keyFile.Value == "" {
+		return nil, errKeyFileNotSet
+	}
+
+	data, err := l.readFile(keyFile.Value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errKeyRead, err)
+	}
+
+	return parseKey(data)
+}
+
+func (l *Loader) readFile(name string) ([]byte, error) {
+	return fs.ReadFile(l.fsys, name)
+}
+
+// profileFiles layers the files of the active profiles over each base file:
+// with APP_PROFILE=staging, config.yaml is followed by config.staging.yaml (or
+// any other supported extension) when it exists. Several profiles can be
+// given comma separated, later ones winning.
+func (l *Loader) profileFiles(files []string) []string {
+	profiles, exist := l.args[profileVar]
+	if !exist {
+		profiles, exist = l.lookup(profileVar)
+	}
+
+	if !exist || strings.TrimSpace(profiles) == "" {
+		return files
+	}
+
+	result := []string{}
+
+	for _, file := range files {
+		result = append(result, file)
+
+		ext := filepath.Ext(file)
+		base := strings.TrimSuffix(file, ext)
+
+		for _, profile := range splitList(profiles) {
+			for _, candidateExt := range append([]string{ext}, profileExtensions...) {
+				candidate := base + "." + profile + candidateExt
+				if _, err := fs.Stat(l.fsys, candidate); err == nil {
+					result = append(result, candidate)
+
+					break
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+func extractArgs(values []string) map[string]string {
+	result := map[string]string{}
+
+	for _, arg := range values {
+		removePrefix := 0
+		if strings.HasPrefix(arg, "-") {
+			removePrefix = 1
+		}
+
+		if !strings.Contains(arg, "=") {
+			continue
+		}
+
+		args := strings.SplitN(arg, "=", 2)
+
+		result[args[0][removePrefix:]] = args[1]
+	}
+
+	return result
+}
+
+func extractFiles(values []string) []string {
+	result := []string{}
+
+	for _, arg := range values {
+		if file, exist := strings.CutPrefix(arg, "-"+fileArg+"="); exist && file != "" {
+			result = append(result, file)
+		}
+	}
+
+	return result
+}
+
+type fileValue struct {
+	value string
+	path  string
+}
+
+func readFiles(readFile func(name string) ([]byte, error), paths []string) (map[string]fileValue, error) {
+	result := map[string]fileValue{}
+
+	for _, path := range paths {
+		data, err := readFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w: %w", config.MODULE_NAME, errFileRead, err)
+		}
+
+		values, err := parseFile(path, data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w: %s: %w", config.MODULE_NAME, errFileParse, path, err)
+		}
+
+		for key, value := range values {
+			result[key] = fileValue{value: value, path: path}
+		}
+	}
+
+	return result, nil
+}
+
+// osFS reads from the OS file system, accepting absolute and relative paths
+// that os.DirFS would reject.
+type osFS struct{}
+
+func (osFS) Open(name string) (fs.File, error) {
+	return os.Open(name)
+}
+
+func (osFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
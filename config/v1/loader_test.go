@@ -0,0 +1,125 @@
+package v1
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"base.yaml":       {Data: []byte("field1: file\nfield2: 1\nfield3: true\n")},
+		"secrets/field_1": {Data: []byte("from-secret\n")},
+	}
+
+	scenarios := []struct {
+		Name        string
+		Loader      *Loader
+		Expected    *testConfig
+		ExpectedErr string
+	}{
+		{
+			Name:     "file",
+			Loader:   NewLoader([]string{"-f=base.yaml"}, nil, fsys),
+			Expected: &testConfig{Field1: "file", Field2: 1, Field3: true},
+		},
+		{
+			Name:     "env_over_file",
+			Loader:   NewLoader([]string{"-f=base.yaml"}, LookupMap(map[string]string{"FIELD_2": "2"}), fsys),
+			Expected: &testConfig{Field1: "file", Field2: 2, Field3: true},
+		},
+		{
+			Name:     "args_over_env",
+			Loader:   NewLoader([]string{"-f=base.yaml", "-FIELD_2=3"}, LookupMap(map[string]string{"FIELD_2": "2"}), fsys),
+			Expected: &testConfig{Field1: "file", Field2: 3, Field3: true},
+		},
+		{
+			Name:     "secret_file",
+			Loader:   NewLoader([]string{"-FIELD_1_FILE=secrets/field_1", "-FIELD_2=1", "-FIELD_3=true"}, nil, fsys),
+			Expected: &testConfig{Field1: "from-secret", Field2: 1, Field3: true},
+		},
+		{
+			Name:        "missing_file",
+			Loader:      NewLoader([]string{"-f=missing.yaml"}, nil, fsys),
+			ExpectedErr: "config: could not read config file: open missing.yaml: file does not exist",
+		},
+		{
+			Name:        "no_sources",
+			Loader:      NewLoader(nil, nil, fsys),
+			ExpectedErr: "config: 3 invalid variables: FIELD_1 (string, required): environment variable not found; FIELD_2 (int, required): environment variable not found; FIELD_3 (bool, required): environment variable not found",
+		},
+	}
+
+	for _, scenario := range scenarios {
+		scenario := scenario
+
+		t.Run(scenario.Name, func(t *testing.T) {
+			t.Parallel()
+
+			env, err := Load[testConfig](scenario.Loader)
+			if scenario.ExpectedErr != "" {
+				assert.Equal(t, scenario.ExpectedErr, err.Error())
+
+				return
+			}
+
+			assert.Equal(t, nil, err)
+			assert.Equal(t, scenario.Expected, env.Get())
+		})
+	}
+}
+
+func TestLoad_NotStruct(t *testing.T) {
+	t.Parallel()
+
+	_, err := Load[string](NewLoader(nil, nil, nil))
+	assert.Equal(t, "config: only structs are supported by config module", err.Error())
+}
+
+func TestLoaderLoadInto(t *testing.T) {
+	t.Parallel()
+
+	loader := NewLoader([]string{"-FIELD_1=a", "-FIELD_2=1", "-FIELD_3=true"}, nil, nil)
+
+	value := &testConfig{}
+	fields, err := loader.LoadInto(value)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &testConfig{Field1: "a", Field2: 1, Field3: true}, value)
+	assert.Len(t, fields, 3)
+
+	_, err = loader.LoadInto(testConfig{})
+	assert.Equal(t, "config: only structs are supported by config module", err.Error())
+
+	_, err = loader.LoadInto((*testConfig)(nil))
+	assert.Equal(t, "config: only structs are supported by config module", err.Error())
+}
+
+func TestDecode_Literal(t *testing.T) {
+	t.Parallel()
+
+	value := &testInterpolateConfig{}
+	err := Decode(value, map[string]string{"HOST": "a ${x}", "ENDPOINT": "enc:foo"})
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &testInterpolateConfig{
+		Host:     "a ${x}",
+		Endpoint: "enc:foo",
+		Backup:   "${HOST}:9000",
+		Fallback: "${MISSING:-${HOST}}",
+		Literal:  "$${HOST}",
+	}, value)
+
+	err = Decode(&testConfig{}, map[string]string{"FIELD_1": "a", "FIELD_2": "x", "FIELD_3": "true"})
+	assert.Equal(t, "config: FIELD_2 (int): could not parse found value to integer: value \"x\"", err.Error())
+
+	assert.Equal(t, "config: only structs are supported by config module", Decode(testConfig{}, nil).Error())
+}
+
+func TestLoaderFiles(t *testing.T) {
+	t.Parallel()
+
+	loader := NewLoader([]string{"-f=a.yaml", "-f=b.env"}, nil, nil)
+	assert.Equal(t, []string{"a.yaml", "b.env"}, loader.Files())
+}
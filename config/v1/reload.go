@@ -0,0 +1,195 @@
+package v1
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/ampliway/way-lib-go/config"
+)
+
+const defaultReloadInterval = 5 * time.Second
+
+var _ config.ReloadableV1[any] = (*Reloader[any])(nil)
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Reloader keeps T up to date: it polls the -f files for changes, polls the
+// remote sources on every tick and reloads on SIGHUP. A reload that fails keeps
+// the previous value and is reported to the OnError handlers.
+type Reloader[T any] struct {
+	value    atomic.Pointer[T]
+	load     func() (*T, error)
+	fsys     fs.FS
+	files    []string
+	poll     bool
+	interval time.Duration
+
+	mux           sync.Mutex
+	reloadMux     sync.Mutex
+	changeHandler []func(old, new *T)
+	errorHandler  []func(err error)
+	stamps        map[string]fileStamp
+
+	hangup    chan os.Signal
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func NewReloader[T any](interval time.Duration) (*Reloader[T], error) {
+	return Watch[T](NewLoader(os.Args[1:], os.LookupEnv, nil), interval)
+}
+
+// Watch is NewReloader for an explicit Loader.
+func Watch[T any](l *Loader, interval time.Duration) (*Reloader[T], error) {
+	loadValue := func() (*T, error) {
+		value, _, err := load[T](l)
+
+		return value, err
+	}
+
+	return newReloader(loadValue, l.fsys, l.files, len(l.sources) > 0, interval)
+}
+
+func newReloader[T any](load func() (*T, error), fsys fs.FS, files []string, poll bool, interval time.Duration) (*Reloader[T], error) {
+	if interval <= 0 {
+		interval = defaultReloadInterval
+	}
+
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Reloader[T]{
+		load:     load,
+		fsys:     fsys,
+		files:    files,
+		poll:     poll,
+		interval: interval,
+		hangup:   make(chan os.Signal, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	r.value.Store(value)
+	r.stamps = r.scan()
+
+	signal.Notify(r.hangup, syscall.SIGHUP)
+
+	go r.watch()
+
+	return r, nil
+}
+
+func (r *Reloader[T]) Get() *T {
+	return r.value.Load()
+}
+
+func (r *Reloader[T]) OnChange(handler func(old, new *T)) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.changeHandler = append(r.changeHandler, handler)
+}
+
+func (r *Reloader[T]) OnError(handler func(err error)) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	r.errorHandler = append(r.errorHandler, handler)
+}
+
+// Reload loads T again and swaps it in when it changed and is valid.
+func (r *Reloader[T]) Reload() error {
+	r.reloadMux.Lock()
+	defer r.reloadMux.Unlock()
+
+	value, err := r.load()
+	if err != nil {
+		r.mux.Lock()
+		handlers := append([]func(error){}, r.errorHandler...)
+		r.mux.Unlock()
+
+		for _, handler := range handlers {
+			handler(err)
+		}
+
+		return err
+	}
+
+	old := r.value.Load()
+	if reflect.DeepEqual(old, value) {
+		return nil
+	}
+
+	r.value.Store(value)
+
+	r.mux.Lock()
+	handlers := append([]func(old, new *T){}, r.changeHandler...)
+	r.mux.Unlock()
+
+	for _, handler := range handlers {
+		handler(old, value)
+	}
+
+	return nil
+}
+
+func (r *Reloader[T]) Close() {
+	r.closeOnce.Do(func() {
+		close(r.stop)
+		<-r.done
+	})
+}
+
+func (r *Reloader[T]) watch() {
+	defer close(r.done)
+	defer signal.Stop(r.hangup)
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-r.hangup:
+			_ = r.Reload()
+		case <-ticker.C:
+			stamps := r.scan()
+			if !r.poll && reflect.DeepEqual(stamps, r.stamps) {
+				continue
+			}
+
+			r.stamps = stamps
+			_ = r.Reload()
+		}
+	}
+}
+
+func (r *Reloader[T]) scan() map[string]fileStamp {
+	result := map[string]fileStamp{}
+
+	for _, path := range r.files {
+		info, err := fs.Stat(r.fsys, path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		if err == nil {
+			result[path] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+
+	return result
+}
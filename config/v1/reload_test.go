@@ -0,0 +1,101 @@
+package v1
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloader(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.env")
+	assert.Equal(t, nil, os.WriteFile(path, []byte("FIELD_1=a\nFIELD_2=1\nFIELD_3=true\n"), 0o600))
+
+	loadFile := func() (*testConfig, error) {
+		values, err := readFiles(os.ReadFile, []string{path})
+		if err != nil {
+			return nil, err
+		}
+
+		r := &resolver{args: map[string]string{}, env: func(string) (string, bool) { return "", false }, files: values}
+		value := testConfig{}
+		r.loadStruct(reflect.ValueOf(&value).Elem(), "")
+
+		if err := r.report.orNil(); err != nil {
+			return nil, err
+		}
+
+		return &value, nil
+	}
+
+	reloader, err := newReloader(loadFile, osFS{}, []string{path}, false, 10*time.Millisecond)
+	assert.Equal(t, nil, err)
+	defer reloader.Close()
+
+	assert.Equal(t, &testConfig{Field1: "a", Field2: 1, Field3: true}, reloader.Get())
+
+	var (
+		mux     sync.Mutex
+		changes [][2]*testConfig
+		errs    []error
+	)
+
+	changed := make(chan struct{}, 1)
+	reloader.OnChange(func(old, new *testConfig) {
+		mux.Lock()
+		defer mux.Unlock()
+
+		changes = append(changes, [2]*testConfig{old, new})
+		changed <- struct{}{}
+	})
+
+	failed := make(chan struct{}, 1)
+	reloader.OnError(func(err error) {
+		mux.Lock()
+		defer mux.Unlock()
+
+		errs = append(errs, err)
+		failed <- struct{}{}
+	})
+
+	assert.Equal(t, nil, os.WriteFile(path, []byte("FIELD_1=b\nFIELD_2=22\nFIELD_3=false\n"), 0o600))
+	waitFor(t, changed)
+
+	mux.Lock()
+	assert.Len(t, changes, 1)
+	assert.Equal(t, &testConfig{Field1: "a", Field2: 1, Field3: true}, changes[0][0])
+	assert.Equal(t, &testConfig{Field1: "b", Field2: 22, Field3: false}, changes[0][1])
+	mux.Unlock()
+
+	assert.Equal(t, nil, os.WriteFile(path, []byte("FIELD_1=b\nFIELD_2=x\nFIELD_3=false\n"), 0o600))
+	waitFor(t, failed)
+
+	mux.Lock()
+	assert.Len(t, errs, 1)
+	assert.True(t, errors.Is(errs[0], errEnvIntParse))
+	mux.Unlock()
+
+	assert.Equal(t, &testConfig{Field1: "b", Field2: 22, Field3: false}, reloader.Get())
+
+	assert.Equal(t, nil, os.WriteFile(path, []byte("FIELD_1=c\nFIELD_2=3\nFIELD_3=true\n"), 0o600))
+	assert.Equal(t, nil, syscall.Kill(os.Getpid(), syscall.SIGHUP))
+	waitFor(t, changed)
+
+	assert.Equal(t, &testConfig{Field1: "c", Field2: 3, Field3: true}, reloader.Get())
+}
+
+func waitFor(t *testing.T, c chan struct{}) {
+	t.Helper()
+
+	select {
+	case <-c:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for reload")
+	}
+}
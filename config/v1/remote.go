@@ -0,0 +1,83 @@
+package v1
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/ampliway/way-lib-go/cache"
+	"github.com/ampliway/way-lib-go/config"
+)
+
+const (
+	defaultRemoteKey = "config"
+	ruleSource       = "source"
+)
+
+var (
+	_ config.SnapshotSource = (*CacheSource)(nil)
+
+	errSourceNil    = errors.New("source cannot be nil")
+	errSourceLookup = errors.New("remote source lookup failed")
+)
+
+// CacheSource reads variables from the cache module, all stored as one JSON
+// document under key and flattened like a JSON config file, so a load costs a
+// single GET. An empty value counts as not set.
+type CacheSource struct {
+	cache cache.V1
+	key   string
+}
+
+func NewCacheSource(c cache.V1, key string) (*CacheSource, error) {
+	if c == nil {
+		return nil, fmt.Errorf("%s: %w", config.MODULE_NAME, errSourceNil)
+	}
+
+	if key == "" {
+		key = defaultRemoteKey
+	}
+
+	return &CacheSource{
+		cache: c,
+		key:   key,
+	}, nil
+}
+
+func (s *CacheSource) Lookup(name string) (string, bool, error) {
+	values, err := s.Snapshot()
+	if err != nil {
+		return "", false, err
+	}
+
+	value, exist := values[name]
+
+	return value, exist, nil
+}
+
+func (s *CacheSource) Snapshot() (map[string]string, error) {
+	data, err := s.cache.Get(s.key)
+	if err != nil {
+		return nil, err
+	}
+
+	result := map[string]string{}
+	if data == "" {
+		return result, nil
+	}
+
+	doc := map[string]any{}
+	if err := json.Unmarshal([]byte(data), &doc); err != nil {
+		return nil, err
+	}
+
+	flatten(result, "", doc)
+
+	for name, value := range result {
+		if value == "" {
+			delete(result, name)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,108 @@
+package v1
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/ampliway/way-lib-go/cache"
+	cacheV1 "github.com/ampliway/way-lib-go/cache/v1"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingCache struct{}
+
+func (failingCache) Set(string, string, time.Duration) error { return nil }
+
+func (failingCache) Get(string) (string, error) { return "", errors.New("connection refused") }
+
+type countingCache struct {
+	cache.V1
+	gets int
+}
+
+func (c *countingCache) Get(key string) (string, error) {
+	c.gets++
+
+	return c.V1.Get(key)
+}
+
+func TestNewCacheSource(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewCacheSource(nil, "")
+	assert.Equal(t, "config: source cannot be nil", err.Error())
+
+	source, err := NewCacheSource(cacheV1.NewMock(), "")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, defaultRemoteKey, source.key)
+}
+
+func TestLoad_CacheSource(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"base.yaml": {Data: []byte("field1: file\nfield2: 1\nfield3: false\n")},
+	}
+
+	cache := &countingCache{V1: cacheV1.NewMock()}
+	assert.Equal(t, nil, cache.Set("app", `{"field1": "remote", "field2": 2, "field3": ""}`, 0))
+
+	source, err := NewCacheSource(cache, "app")
+	assert.Equal(t, nil, err)
+
+	loader := NewLoader([]string{"-f=base.yaml"}, LookupMap(map[string]string{"FIELD_2": "3"}), fsys)
+	assert.Equal(t, nil, loader.AddSource(source))
+	assert.Equal(t, "config: source cannot be nil", loader.AddSource(nil).Error())
+
+	env, err := Load[testConfig](loader)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &testConfig{Field1: "remote", Field2: 3, Field3: false}, env.Get())
+	assert.Equal(t, []Field{
+		{Name: "FIELD_1", Type: "string", Source: SourceRemote, Value: "remote"},
+		{Name: "FIELD_2", Type: "int", Source: SourceEnv, Value: "3"},
+		{Name: "FIELD_3", Type: "bool", Source: SourceFile, Origin: "base.yaml", Value: "false"},
+	}, env.Fields())
+	assert.Equal(t, 1, cache.gets)
+}
+
+func TestLoad_CacheSourceError(t *testing.T) {
+	t.Parallel()
+
+	source, err := NewCacheSource(failingCache{}, "")
+	assert.Equal(t, nil, err)
+
+	loader := NewLoader([]string{"-FIELD_1=a", "-FIELD_2=1"}, nil, nil)
+	assert.Equal(t, nil, loader.AddSource(source))
+
+	_, err = Load[testConfig](loader)
+	assert.Equal(t, "config: FIELD_3 (bool, source): remote source lookup failed: connection refused", err.Error())
+}
+
+func TestWatch_CacheSource(t *testing.T) {
+	t.Parallel()
+
+	cache := cacheV1.NewMock()
+	assert.Equal(t, nil, cache.Set("config", `{"FIELD_2": "1"}`, 0))
+
+	source, err := NewCacheSource(cache, "")
+	assert.Equal(t, nil, err)
+
+	loader := NewLoader([]string{"-FIELD_1=a", "-FIELD_3=true"}, nil, fstest.MapFS{})
+	assert.Equal(t, nil, loader.AddSource(source))
+
+	reloader, err := Watch[testConfig](loader, 10*time.Millisecond)
+	assert.Equal(t, nil, err)
+	defer reloader.Close()
+
+	changed := make(chan struct{}, 1)
+	reloader.OnChange(func(old, new *testConfig) {
+		changed <- struct{}{}
+	})
+
+	assert.Equal(t, nil, cache.Set("config", `{"FIELD_2": "5"}`, 0))
+	waitFor(t, changed)
+
+	assert.Equal(t, &testConfig{Field1: "a", Field2: 5, Field3: true}, reloader.Get())
+}
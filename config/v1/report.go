@@ -0,0 +1,82 @@
+package v1
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ampliway/way-lib-go/config"
+)
+
+const ruleRequired = "required"
+
+// FieldError describes one variable that could not be loaded or validated.
+type FieldError struct {
+	Name  string
+	Type  string
+	Rule  string
+	Value string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	var b strings.Builder
+
+	b.WriteString(e.Name)
+
+	switch {
+	case e.Type != "" && e.Rule != "":
+		fmt.Fprintf(&b, " (%s, %s)", e.Type, e.Rule)
+	case e.Type != "":
+		fmt.Fprintf(&b, " (%s)", e.Type)
+	}
+
+	fmt.Fprintf(&b, ": %s", e.Err)
+
+	if e.Value != "" {
+		fmt.Fprintf(&b, ": value \"%s\"", e.Value)
+	}
+
+	return b.String()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Errors is the report returned by the loader, listing every offending
+// variable instead of only the first one.
+type Errors []*FieldError
+
+func (e Errors) Error() string {
+	items := make([]string, 0, len(e))
+	for _, err := range e {
+		items = append(items, err.Error())
+	}
+
+	if len(items) == 1 {
+		return fmt.Sprintf("%s: %s", config.MODULE_NAME, items[0])
+	}
+
+	return fmt.Sprintf("%s: %d invalid variables: %s", config.MODULE_NAME, len(items), strings.Join(items, "; "))
+}
+
+func (e Errors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, err := range e {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+func (e *Errors) add(err *FieldError) {
+	*e = append(*e, err)
+}
+
+func (e Errors) orNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+
+	return e
+}
@@ -0,0 +1,223 @@
+package v1
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ampliway/way-lib-go/config"
+)
+
+// resolver looks up variables through the configured layers. Precedence from
+// lowest to highest is: struct defaults, -f files, remote sources,
+// environment, CLI args. A literal resolver takes values and defaults as they
+// are.
+type resolver struct {
+	literal  bool
+	args     map[string]string
+	env      func(key string) (string, bool)
+	sources  []config.Source
+	files    map[string]fileValue
+	readFile func(name string) ([]byte, error)
+	key      func() ([]byte, error)
+	keyCache []byte
+	fetched  map[int]sourceSnapshot
+	report   Errors
+	fields   []Field
+}
+
+// layer is one level of the precedence order.
+type layer struct {
+	source string
+	lookup func(name string) (value, origin string, exist bool, err error)
+}
+
+func (l layer) field(name string) (*Field, bool, error) {
+	value, origin, exist, err := l.lookup(name)
+	if err != nil || !exist {
+		return nil, false, err
+	}
+
+	return &Field{Name: name, Value: value, Source: l.source, Origin: origin}, true, nil
+}
+
+// layers lists the layers from highest to lowest precedence.
+func (r *resolver) layers() []layer {
+	result := []layer{
+		{source: SourceArgs, lookup: func(name string) (string, string, bool, error) {
+			value, exist := r.args[name]
+
+			return value, "", exist, nil
+		}},
+		{source: SourceEnv, lookup: func(name string) (string, string, bool, error) {
+			value, exist := r.env(name)
+
+			return value, "", exist, nil
+		}},
+	}
+
+	for i := len(r.sources) - 1; i >= 0; i-- {
+		i := i
+
+		result = append(result, layer{source: SourceRemote, lookup: func(name string) (string, string, bool, error) {
+			value, exist, err := r.lookupSource(i, name)
+			if err != nil {
+				return "", "", false, fmt.Errorf("%w: %w", errSourceLookup, err)
+			}
+
+			return value, "", exist, nil
+		}})
+	}
+
+	return append(result, layer{source: SourceFile, lookup: func(name string) (string, string, bool, error) {
+		value, exist := r.files[name]
+
+		return value.value, value.path, exist, nil
+	}})
+}
+
+type sourceSnapshot struct {
+	values map[string]string
+	err    error
+}
+
+// lookupSource reads a SnapshotSource once per load, and asks other sources
+// for each name.
+func (r *resolver) lookupSource(i int, name string) (string, bool, error) {
+	source, ok := r.sources[i].(config.SnapshotSource)
+	if !ok {
+		return r.sources[i].Lookup(name)
+	}
+
+	snapshot, exist := r.fetched[i]
+	if !exist {
+		snapshot.values, snapshot.err = source.Snapshot()
+
+		if r.fetched == nil {
+			r.fetched = map[int]sourceSnapshot{}
+		}
+
+		r.fetched[i] = snapshot
+	}
+
+	if snapshot.err != nil {
+		return "", false, snapshot.err
+	}
+
+	value, exist := snapshot.values[name]
+
+	return value, exist, nil
+}
+
+func (r *resolver) lookup(name string) (*Field, bool, error) {
+	for _, l := range r.layers() {
+		field, exist, err := l.field(name)
+		if err != nil || exist {
+			return field, exist, err
+		}
+	}
+
+	return nil, false, nil
+}
+
+// resolve returns the value of name from the highest layer holding either
+// NAME or NAME_FILE, the latter read from the file it points to, and expands
+// "<scheme>://<name>" references through the registered secret resolvers.
+func (r *resolver) resolve(name string) (*Field, error) {
+	if r.literal {
+		field, _, err := r.lookup(name)
+
+		return field, err
+	}
+
+	var field *Field
+
+	for _, l := range r.layers() {
+		layerField, exist, err := r.resolveLayer(l, name)
+		if err != nil {
+			return nil, err
+		}
+
+		if exist {
+			field = layerField
+
+			break
+		}
+	}
+
+	if field == nil {
+		return nil, nil
+	}
+
+	if secretResolver, secretName, found := findSecretResolver(field.Value); found {
+		secret, err := secretResolver.Resolve(secretName)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", errSecretResolve, err)
+		}
+
+		field.Origin = field.Value
+		field.Value = secret
+		field.Secret = true
+	}
+
+	if strings.HasPrefix(field.Value, EncryptedPrefix) {
+		plain, err := r.decrypt(field.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		field.Value = plain
+		field.Secret = true
+	}
+
+	return field, nil
+}
+
+// resolveLayer looks up name in one layer, then NAME_FILE.
+func (r *resolver) resolveLayer(l layer, name string) (*Field, bool, error) {
+	field, exist, err := l.field(name)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if exist {
+		value, err := r.interpolateField(field, []string{name})
+		if err != nil {
+			return nil, false, err
+		}
+
+		field.Value = value
+
+		return field, true, nil
+	}
+
+	pathField, exist, err := l.field(name + secretFileSuffix)
+	if err != nil || !exist {
+		return nil, false, err
+	}
+
+	data, err := r.readFile(pathField.Value)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %s%s: %w", errSecretFileRead, name, secretFileSuffix, err)
+	}
+
+	return &Field{
+		Name:   name,
+		Value:  trimSecret(data),
+		Source: pathField.Source,
+		Origin: name + secretFileSuffix,
+		Secret: true,
+	}, true, nil
+}
+
+func (r *resolver) decrypt(value string) (string, error) {
+	if r.keyCache == nil {
+		key, err := r.key()
+		if err != nil {
+			return "", err
+		}
+
+		r.keyCache = key
+	}
+
+	return decrypt(r.keyCache, value)
+}
@@ -0,0 +1,349 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ampliway/way-lib-go/config"
+)
+
+const (
+	jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+	durationFormat  = "go-duration"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	urlType      = reflect.TypeOf(url.URL{})
+)
+
+// Variable describes one variable expected by a config struct, without
+// loading it.
+type Variable struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Required    bool     `json:"required"`
+	Default     string   `json:"default,omitempty"`
+	HasDefault  bool     `json:"has_default"`
+	Secret      bool     `json:"secret"`
+	Rules       []string `json:"rules,omitempty"`
+	Description string   `json:"description,omitempty"`
+
+	typ   reflect.Type
+	rules []rule
+}
+
+// Describe reflects T and lists the variables it expects.
+func Describe[T any]() ([]Variable, error) {
+	return DescribeType(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+func DescribeType(t reflect.Type) ([]Variable, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s: %w", config.MODULE_NAME, errGenericNotSupported)
+	}
+
+	report := Errors{}
+	vars := []Variable{}
+
+	for _, spec := range fieldSpecs(t, "") {
+		if spec.err != nil {
+			report.add(&FieldError{Name: spec.name, Type: spec.typ.String(), Err: spec.err})
+
+			continue
+		}
+
+		rules := make([]string, 0, len(spec.tags.rules))
+		for _, r := range spec.tags.rules {
+			rules = append(rules, r.String())
+		}
+
+		vars = append(vars, Variable{
+			Name:        spec.name,
+			Type:        spec.typ.String(),
+			Required:    spec.tags.required,
+			Default:     spec.tags.defaultVal,
+			HasDefault:  spec.tags.hasDefault,
+			Secret:      spec.tags.isSecret(spec.name),
+			Rules:       rules,
+			Description: spec.tags.desc,
+			typ:         spec.typ,
+			rules:       spec.tags.rules,
+		})
+	}
+
+	if err := report.orNil(); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
+// JSONSchema renders the variables as a JSON Schema object whose properties are
+// the variable names.
+func JSONSchema(vars []Variable) ([]byte, error) {
+	properties := map[string]any{}
+	required := []string{}
+
+	for _, v := range vars {
+		properties[v.Name] = v.schema()
+
+		if v.Required {
+			required = append(required, v.Name)
+		}
+	}
+
+	return json.MarshalIndent(map[string]any{
+		"$schema":              jsonSchemaDraft,
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": true,
+	}, "", "  ")
+}
+
+func (v Variable) schema() map[string]any {
+	schema := typeSchema(v.typ)
+
+	if v.Description != "" {
+		schema["description"] = v.Description
+	}
+
+	if v.Secret {
+		schema["writeOnly"] = true
+	}
+
+	if v.HasDefault {
+		schema["default"] = schemaValue(schema["type"], v.typ, v.Default)
+	}
+
+	for _, r := range v.rules {
+		switch r.name {
+		case tagMin, tagMax:
+			if schema["format"] == durationFormat {
+				schema["x-"+r.name] = r.arg
+
+				continue
+			}
+
+			schema[boundKeyword(schema["type"], r.name)] = boundValue(schema["type"], v.typ, r.arg)
+		case tagOneOf:
+			enum := []any{}
+			for _, option := range strings.Fields(r.arg) {
+				enum = append(enum, schemaValue(schema["type"], v.typ, option))
+			}
+
+			schema["enum"] = enum
+		case tagRegex:
+			schema["pattern"] = r.arg
+		case tagURL:
+			schema["format"] = "uri"
+		case tagNonEmpty:
+			schema[boundKeyword(schema["type"], tagMin)] = 1
+		}
+	}
+
+	return schema
+}
+
+func typeSchema(t reflect.Type) map[string]any {
+	if t.Kind() == reflect.Ptr && t.Elem().Kind() != reflect.Struct {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == durationType:
+		return map[string]any{"type": "string", "format": durationFormat}
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == urlType || t == reflect.PointerTo(urlType):
+		return map[string]any{"type": "string", "format": "uri"}
+	case isDecodable(t):
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string"}
+		}
+
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	default:
+		return map[string]any{"type": "string"}
+	}
+}
+
+func boundKeyword(schemaType any, name string) string {
+	keywords := map[any][2]string{
+		"string": {"minLength", "maxLength"},
+		"array":  {"minItems", "maxItems"},
+		"object": {"minProperties", "maxProperties"},
+	}
+
+	pair, exist := keywords[schemaType]
+	if !exist {
+		pair = [2]string{"minimum", "maximum"}
+	}
+
+	if name == tagMin {
+		return pair[0]
+	}
+
+	return pair[1]
+}
+
+func boundValue(schemaType any, t reflect.Type, raw string) any {
+	switch schemaType {
+	case "string", "array", "object":
+		if length, err := strconv.Atoi(raw); err == nil {
+			return length
+		}
+
+		return raw
+	default:
+		return schemaValue(schemaType, t, raw)
+	}
+}
+
+// schemaValue converts a raw tag value to the JSON type of the schema.
+func schemaValue(schemaType any, t reflect.Type, raw string) any {
+	switch schemaType {
+	case "integer":
+		if v, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return v
+		}
+	case "number":
+		if v, err := strconv.ParseFloat(raw, 64); err == nil {
+			return v
+		}
+	case "boolean":
+		if v, err := strconv.ParseBool(raw); err == nil {
+			return v
+		}
+	case "array":
+		if t.Kind() == reflect.Slice {
+			items := []any{}
+			for _, item := range splitList(raw) {
+				items = append(items, schemaValue(typeSchema(t.Elem())["type"], t.Elem(), item))
+			}
+
+			return items
+		}
+	case "object":
+		result := map[string]any{}
+		for _, item := range splitList(raw) {
+			pair := strings.SplitN(item, keyValueSeparator, 2)
+			if len(pair) == 2 {
+				result[strings.TrimSpace(pair[0])] = strings.TrimSpace(pair[1])
+			}
+		}
+
+		return result
+	}
+
+	return raw
+}
+
+// EnvExample renders a commented .env file. Required variables are left
+// uncommented and empty, the others are commented out with their default.
+func EnvExample(vars []Variable) []byte {
+	b := &bytes.Buffer{}
+
+	for i, v := range vars {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+
+		if v.Description != "" {
+			fmt.Fprintf(b, "# %s\n", v.Description)
+		}
+
+		fmt.Fprintf(b, "# %s\n", strings.Join(v.summary(), ", "))
+
+		if v.Required {
+			fmt.Fprintf(b, "%s=\n", v.Name)
+
+			continue
+		}
+
+		fmt.Fprintf(b, "# %s=%s\n", v.Name, v.Default)
+	}
+
+	return b.Bytes()
+}
+
+// Markdown renders the variables as a Markdown table.
+func Markdown(vars []Variable) []byte {
+	b := &bytes.Buffer{}
+
+	b.WriteString("| Variable | Type | Required | Default | Rules | Secret | Description |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- | --- |\n")
+
+	for _, v := range vars {
+		defaultValue := ""
+		if v.HasDefault {
+			defaultValue = fmt.Sprintf("`%s`", v.Default)
+		}
+
+		fmt.Fprintf(b, "| `%s` | `%s` | %s | %s | %s | %s | %s |\n",
+			v.Name,
+			v.Type,
+			yesNo(v.Required),
+			markdownEscape(defaultValue),
+			markdownEscape(strings.Join(v.Rules, ", ")),
+			yesNo(v.Secret),
+			markdownEscape(v.Description),
+		)
+	}
+
+	return b.Bytes()
+}
+
+func (v Variable) summary() []string {
+	summary := []string{v.Type}
+
+	if v.Required {
+		summary = append(summary, ruleRequired)
+	} else {
+		summary = append(summary, "optional")
+	}
+
+	if v.HasDefault {
+		summary = append(summary, fmt.Sprintf("default \"%s\"", v.Default))
+	}
+
+	if v.Secret {
+		summary = append(summary, tagSecret)
+	}
+
+	return append(summary, v.Rules...)
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+
+	return "no"
+}
+
+func markdownEscape(value string) string {
+	return strings.ReplaceAll(value, "|", "\\|")
+}
@@ -0,0 +1,132 @@
+package v1
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testSchemaConfig struct {
+	SchemaHost     string        `desc:"Database host" nonempty:"true"`
+	SchemaPort     int           `default:"5432" min:"1" max:"65535"`
+	SchemaTimeout  time.Duration `default:"5s" min:"1s"`
+	SchemaLevel    string        `default:"info" oneof:"debug info"`
+	SchemaHosts    []string      `required:"false"`
+	SchemaPassword string
+	Nested         struct {
+		Ratio float64 `default:"0.5"`
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	t.Parallel()
+
+	vars, err := Describe[testSchemaConfig]()
+	assert.Equal(t, nil, err)
+
+	names := []string{}
+	for _, v := range vars {
+		names = append(names, v.Name)
+	}
+
+	assert.Equal(t, []string{
+		"SCHEMA_HOST", "SCHEMA_PORT", "SCHEMA_TIMEOUT", "SCHEMA_LEVEL", "SCHEMA_HOSTS", "SCHEMA_PASSWORD", "NESTED_RATIO",
+	}, names)
+
+	assert.Equal(t, "SCHEMA_PORT", vars[1].Name)
+	assert.Equal(t, "int", vars[1].Type)
+	assert.False(t, vars[1].Required)
+	assert.Equal(t, "5432", vars[1].Default)
+	assert.Equal(t, []string{"min=1", "max=65535"}, vars[1].Rules)
+	assert.True(t, vars[5].Secret)
+
+	_, err = Describe[string]()
+	assert.Equal(t, "config: only structs are supported by config module", err.Error())
+
+	_, err = Describe[testInvalidTagConfig]()
+	assert.Equal(t, "config: FIELD (string): invalid struct tag: required:\"maybe\"", err.Error())
+}
+
+func TestJSONSchema(t *testing.T) {
+	t.Parallel()
+
+	vars, err := Describe[testSchemaConfig]()
+	assert.Equal(t, nil, err)
+
+	data, err := JSONSchema(vars)
+	assert.Equal(t, nil, err)
+
+	expected := `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"type": "object",
+		"additionalProperties": true,
+		"required": ["SCHEMA_HOST", "SCHEMA_PASSWORD"],
+		"properties": {
+			"SCHEMA_HOST": {"type": "string", "description": "Database host", "minLength": 1},
+			"SCHEMA_PORT": {"type": "integer", "default": 5432, "minimum": 1, "maximum": 65535},
+			"SCHEMA_TIMEOUT": {"type": "string", "format": "go-duration", "default": "5s", "x-min": "1s"},
+			"SCHEMA_LEVEL": {"type": "string", "default": "info", "enum": ["debug", "info"]},
+			"SCHEMA_HOSTS": {"type": "array", "items": {"type": "string"}},
+			"SCHEMA_PASSWORD": {"type": "string", "writeOnly": true},
+			"NESTED_RATIO": {"type": "number", "default": 0.5}
+		}
+	}`
+
+	actual := map[string]any{}
+	assert.Equal(t, nil, json.Unmarshal(data, &actual))
+
+	expectedMap := map[string]any{}
+	assert.Equal(t, nil, json.Unmarshal([]byte(expected), &expectedMap))
+
+	assert.Equal(t, expectedMap, actual)
+}
+
+func TestEnvExample(t *testing.T) {
+	t.Parallel()
+
+	vars, err := Describe[testSchemaConfig]()
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, ""+
+		"# Database host\n"+
+		"# string, required, nonempty\n"+
+		"SCHEMA_HOST=\n"+
+		"\n"+
+		"# int, optional, default \"5432\", min=1, max=65535\n"+
+		"# SCHEMA_PORT=5432\n"+
+		"\n"+
+		"# time.Duration, optional, default \"5s\", min=1s\n"+
+		"# SCHEMA_TIMEOUT=5s\n"+
+		"\n"+
+		"# string, optional, default \"info\", oneof=debug info\n"+
+		"# SCHEMA_LEVEL=info\n"+
+		"\n"+
+		"# []string, optional\n"+
+		"# SCHEMA_HOSTS=\n"+
+		"\n"+
+		"# string, required, secret\n"+
+		"SCHEMA_PASSWORD=\n"+
+		"\n"+
+		"# float64, optional, default \"0.5\"\n"+
+		"# NESTED_RATIO=0.5\n", string(EnvExample(vars)))
+}
+
+func TestMarkdown(t *testing.T) {
+	t.Parallel()
+
+	vars, err := Describe[testSchemaConfig]()
+	assert.Equal(t, nil, err)
+
+	assert.Equal(t, ""+
+		"| Variable | Type | Required | Default | Rules | Secret | Description |\n"+
+		"| --- | --- | --- | --- | --- | --- | --- |\n"+
+		"| `SCHEMA_HOST` | `string` | yes |  | nonempty | no | Database host |\n"+
+		"| `SCHEMA_PORT` | `int` | no | `5432` | min=1, max=65535 | no |  |\n"+
+		"| `SCHEMA_TIMEOUT` | `time.Duration` | no | `5s` | min=1s | no |  |\n"+
+		"| `SCHEMA_LEVEL` | `string` | no | `info` | oneof=debug info | no |  |\n"+
+		"| `SCHEMA_HOSTS` | `[]string` | no |  |  | no |  |\n"+
+		"| `SCHEMA_PASSWORD` | `string` | yes |  |  | yes |  |\n"+
+		"| `NESTED_RATIO` | `float64` | no | `0.5` |  | no |  |\n", string(Markdown(vars)))
+}
@@ -0,0 +1,109 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/ampliway/way-lib-go/config"
+)
+
+const (
+	secretFileSuffix    = "_FILE"
+	secretSchemeSep     = "://"
+	ruleSecret          = "secret"
+	defaultSecretScheme = "secret"
+)
+
+var (
+	_ config.SecretResolver = (*SecretDir)(nil)
+
+	errSecretFileRead    = errors.New("could not read secret file")
+	errSecretResolve     = errors.New("could not resolve secret")
+	errSecretResolverNil = errors.New("secret resolver cannot be nil")
+	errSecretSchemeEmpty = errors.New("secret resolver scheme cannot be empty")
+	errSecretNameInvalid = errors.New("invalid secret name")
+	errSecretDirEmpty    = errors.New("secret directory cannot be empty")
+	secretResolversMux   sync.RWMutex
+	secretResolvers      = map[string]config.SecretResolver{}
+)
+
+// RegisterSecretResolver makes values written as "<scheme>://<name>" resolve
+// through resolver when a config is loaded.
+func RegisterSecretResolver(resolver config.SecretResolver) error {
+	if resolver == nil {
+		return fmt.Errorf("%s: %w", config.MODULE_NAME, errSecretResolverNil)
+	}
+
+	if resolver.Scheme() == "" {
+		return fmt.Errorf("%s: %w", config.MODULE_NAME, errSecretSchemeEmpty)
+	}
+
+	secretResolversMux.Lock()
+	defer secretResolversMux.Unlock()
+
+	secretResolvers[resolver.Scheme()] = resolver
+
+	return nil
+}
+
+func findSecretResolver(value string) (config.SecretResolver, string, bool) {
+	scheme, name, found := strings.Cut(value, secretSchemeSep)
+	if !found {
+		return nil, "", false
+	}
+
+	secretResolversMux.RLock()
+	defer secretResolversMux.RUnlock()
+
+	resolver, exist := secretResolvers[scheme]
+
+	return resolver, name, exist
+}
+
+// SecretDir resolves secrets from files in a directory, one file per secret,
+// the layout used by Docker and Kubernetes secret mounts.
+type SecretDir struct {
+	scheme string
+	dir    string
+}
+
+func NewSecretDir(scheme, dir string) (*SecretDir, error) {
+	if scheme == "" {
+		scheme = defaultSecretScheme
+	}
+
+	if dir == "" {
+		return nil, fmt.Errorf("%s: %w", config.MODULE_NAME, errSecretDirEmpty)
+	}
+
+	return &SecretDir{
+		scheme: scheme,
+		dir:    dir,
+	}, nil
+}
+
+func (s *SecretDir) Scheme() string {
+	return s.scheme
+}
+
+func (s *SecretDir) Resolve(name string) (string, error) {
+	if !fs.ValidPath(name) {
+		return "", fmt.Errorf("%w: %s", errSecretNameInvalid, name)
+	}
+
+	data, err := os.ReadFile(filepath.Join(s.dir, filepath.FromSlash(name)))
+	if err != nil {
+		return "", err
+	}
+
+	return trimSecret(data), nil
+}
+
+func trimSecret(data []byte) string {
+	return strings.TrimRight(string(data), "\r\n")
+}
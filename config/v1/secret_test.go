@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testSecretConfig struct {
+	SecretPassword string
+	SecretToken    string
+	SecretPlain    string
+}
+
+func TestNew_Secrets(t *testing.T) {
+	dir := t.TempDir()
+	assert.Equal(t, nil, os.WriteFile(filepath.Join(dir, "password"), []byte("p@ss\n"), 0o600))
+	assert.Equal(t, nil, os.WriteFile(filepath.Join(dir, "token"), []byte("t0ken"), 0o600))
+
+	secretDir, err := NewSecretDir("vault", dir)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, nil, RegisterSecretResolver(secretDir))
+
+	t.Setenv("SECRET_PASSWORD_FILE", filepath.Join(dir, "password"))
+	t.Setenv("SECRET_TOKEN", "vault://token")
+	t.Setenv("SECRET_PLAIN", "https://example.com")
+
+	env, err := New[testSecretConfig]()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &testSecretConfig{
+		SecretPassword: "p@ss",
+		SecretToken:    "t0ken",
+		SecretPlain:    "https://example.com",
+	}, env.Get())
+
+	t.Setenv("SECRET_PASSWORD_FILE", filepath.Join(dir, "missing"))
+	t.Setenv("SECRET_TOKEN", "vault://../password")
+
+	_, err = New[testSecretConfig]()
+	assert.ErrorIs(t, err, errSecretFileRead)
+	assert.ErrorIs(t, err, errSecretNameInvalid)
+}
+
+func TestLoad_SecretFilePrecedence(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"config.yaml":      {Data: []byte("secret_password: from-file\nsecret_token: from-file\nsecret_plain: from-file\n")},
+		"secrets/password": {Data: []byte("from-env-file\n")},
+		"secrets/token":    {Data: []byte("from-env-file\n")},
+	}
+	lookup := LookupMap(map[string]string{
+		"SECRET_PASSWORD_FILE": "secrets/password",
+		"SECRET_TOKEN_FILE":    "secrets/token",
+	})
+
+	loader := NewLoader([]string{"-f=config.yaml", "-SECRET_TOKEN=from-args"}, lookup, fsys)
+
+	env, err := Load[testSecretConfig](loader)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &testSecretConfig{
+		SecretPassword: "from-env-file",
+		SecretToken:    "from-args",
+		SecretPlain:    "from-file",
+	}, env.Get())
+}
+
+func TestRegisterSecretResolver_Invalid(t *testing.T) {
+	t.Parallel()
+
+	err := RegisterSecretResolver(nil)
+	assert.Equal(t, "config: secret resolver cannot be nil", err.Error())
+
+	err = RegisterSecretResolver(&SecretDir{dir: "/run/secrets"})
+	assert.Equal(t, "config: secret resolver scheme cannot be empty", err.Error())
+
+	_, err = NewSecretDir("", "")
+	assert.Equal(t, "config: secret directory cannot be empty", err.Error())
+
+	secretDir, err := NewSecretDir("", "/run/secrets")
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "secret", secretDir.Scheme())
+}
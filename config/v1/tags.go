@@ -0,0 +1,174 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/iancoleman/strcase"
+)
+
+const (
+	tagEnv      = "env"
+	tagDefault  = "default"
+	tagRequired = "required"
+	tagDesc     = "desc"
+
+	tagSkip = "-"
+)
+
+var errTagInvalid = errors.New("invalid struct tag")
+
+type fieldTags struct {
+	name       string
+	skip       bool
+	defaultVal string
+	hasDefault bool
+	required   bool
+	secret     bool
+	hasSecret  bool
+	desc       string
+	rules      []rule
+}
+
+func parseTags(f reflect.StructField) (*fieldTags, error) {
+	tags := &fieldTags{
+		name:     strcase.ToScreamingSnake(f.Name),
+		required: true,
+	}
+
+	if name, exist := f.Tag.Lookup(tagEnv); exist {
+		if name == tagSkip {
+			tags.skip = true
+
+			return tags, nil
+		}
+
+		if name != "" {
+			tags.name = name
+		}
+	}
+
+	tags.desc = f.Tag.Get(tagDesc)
+	tags.defaultVal, tags.hasDefault = f.Tag.Lookup(tagDefault)
+	if tags.hasDefault {
+		tags.required = false
+	}
+
+	if required, exist := f.Tag.Lookup(tagRequired); exist {
+		value, err := strconv.ParseBool(required)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s:\"%s\"", errTagInvalid, tagRequired, required)
+		}
+
+		tags.required = value && !tags.hasDefault
+	}
+
+	if secret, exist := f.Tag.Lookup(tagSecret); exist {
+		value, err := strconv.ParseBool(secret)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s:\"%s\"", errTagInvalid, tagSecret, secret)
+		}
+
+		tags.secret, tags.hasSecret = value, true
+	}
+
+	rules, err := parseRules(f.Tag)
+	if err != nil {
+		return nil, err
+	}
+
+	tags.rules = rules
+
+	return tags, nil
+}
+
+func (t *fieldTags) isSecret(envName string) bool {
+	if t.hasSecret {
+		return t.secret
+	}
+
+	return IsSecretName(envName)
+}
+
+type fieldSpec struct {
+	name  string
+	index []int
+	typ   reflect.Type
+	tags  *fieldTags
+	err   error
+}
+
+// fieldSpecs lists the variables of t. Nested structs and pointers to structs
+// are resolved recursively with the parent field name as prefix (Database.Host
+// -> DATABASE_HOST); embedded structs keep no prefix. A struct reaching itself
+// again (type Node struct{ Next *Node }) is reported instead of followed.
+func fieldSpecs(t reflect.Type, prefix string) []fieldSpec {
+	return appendFieldSpecs([]fieldSpec{}, t, prefix, nil, []reflect.Type{t})
+}
+
+func appendFieldSpecs(specs []fieldSpec, t reflect.Type, prefix string, index []int, visiting []reflect.Type) []fieldSpec {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !(f.Anonymous && f.Type.Kind() == reflect.Struct) {
+			continue
+		}
+
+		fieldIndex := append(append([]int{}, index...), i)
+
+		tags, err := parseTags(f)
+		if err != nil {
+			specs = append(specs, fieldSpec{
+				name:  envFieldName(prefix, strcase.ToScreamingSnake(f.Name)),
+				index: fieldIndex,
+				typ:   f.Type,
+				err:   err,
+			})
+
+			continue
+		}
+
+		if tags.skip {
+			continue
+		}
+
+		envName := envFieldName(prefix, tags.name)
+
+		if isNestedStruct(f.Type) {
+			nestedPrefix := envName
+			if f.Anonymous && f.Tag.Get(tagEnv) == "" {
+				nestedPrefix = prefix
+			}
+
+			nestedType := f.Type
+			if nestedType.Kind() == reflect.Ptr {
+				nestedType = nestedType.Elem()
+			}
+
+			if containsType(visiting, nestedType) {
+				specs = append(specs, fieldSpec{name: envName, index: fieldIndex, typ: f.Type, err: errFieldCycle})
+
+				continue
+			}
+
+			specs = appendFieldSpecs(specs, nestedType, nestedPrefix, fieldIndex, append(visiting[:len(visiting):len(visiting)], nestedType))
+
+			continue
+		}
+
+		specs = append(specs, fieldSpec{name: envName, index: fieldIndex, typ: f.Type, tags: tags})
+	}
+
+	return specs
+}
+
+func containsType(types []reflect.Type, t reflect.Type) bool {
+	for _, item := range types {
+		if item == t {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,51 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testTagsConfig struct {
+	Legacy   string        `env:"OLD_STYLE_NAME"`
+	Timeout  time.Duration `default:"5s"`
+	Optional string        `required:"false"`
+	Ignored  string        `env:"-"`
+	Nested   struct {
+		Port int `default:"8080"`
+	} `env:"HTTP"`
+}
+
+type testInvalidTagConfig struct {
+	Field string `required:"maybe"`
+}
+
+func TestNew_Tags(t *testing.T) {
+	_, err := New[testTagsConfig]()
+	assert.Equal(t, "config: OLD_STYLE_NAME (string, required): environment variable not found", err.Error())
+
+	t.Setenv("OLD_STYLE_NAME", "legacy")
+	t.Setenv("IGNORED", "ignored")
+
+	env, err := New[testTagsConfig]()
+	assert.Equal(t, nil, err)
+
+	expected := &testTagsConfig{Legacy: "legacy", Timeout: 5 * time.Second}
+	expected.Nested.Port = 8080
+	assert.Equal(t, expected, env.Get())
+
+	t.Setenv("TIMEOUT", "1s")
+	t.Setenv("OPTIONAL", "set")
+	t.Setenv("HTTP_PORT", "9090")
+
+	env, err = New[testTagsConfig]()
+	assert.Equal(t, nil, err)
+
+	expected = &testTagsConfig{Legacy: "legacy", Timeout: time.Second, Optional: "set"}
+	expected.Nested.Port = 9090
+	assert.Equal(t, expected, env.Get())
+
+	_, err = New[testInvalidTagConfig]()
+	assert.Equal(t, "config: FIELD (string): invalid struct tag: required:\"maybe\"", err.Error())
+}
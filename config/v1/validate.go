@@ -0,0 +1,210 @@
+package v1
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	tagMin      = "min"
+	tagMax      = "max"
+	tagOneOf    = "oneof"
+	tagRegex    = "regex"
+	tagURL      = "url"
+	tagNonEmpty = "nonempty"
+
+	oneOfSeparator = " "
+)
+
+var (
+	ruleTags = []string{tagMin, tagMax, tagOneOf, tagRegex, tagURL, tagNonEmpty}
+
+	errRuleMin       = errors.New("value is below minimum")
+	errRuleMax       = errors.New("value is above maximum")
+	errRuleOneOf     = errors.New("value is not one of the allowed values")
+	errRuleRegex     = errors.New("value does not match pattern")
+	errRuleURL       = errors.New("value is not an absolute url")
+	errRuleNonEmpty  = errors.New("value cannot be empty")
+	errRuleInvalid   = errors.New("invalid rule")
+	errValidateFails = errors.New("validation failed")
+)
+
+type validator interface {
+	Validate() error
+}
+
+type rule struct {
+	name string
+	arg  string
+}
+
+func (r rule) String() string {
+	switch r.name {
+	case tagURL, tagNonEmpty:
+		return r.name
+	default:
+		return fmt.Sprintf("%s=%s", r.name, r.arg)
+	}
+}
+
+func parseRules(tag reflect.StructTag) ([]rule, error) {
+	rules := []rule{}
+
+	for _, name := range ruleTags {
+		arg, exist := tag.Lookup(name)
+		if !exist {
+			continue
+		}
+
+		if name == tagURL || name == tagNonEmpty {
+			enabled, err := strconv.ParseBool(arg)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s:\"%s\"", errTagInvalid, name, arg)
+			}
+
+			if !enabled {
+				continue
+			}
+		}
+
+		rules = append(rules, rule{name: name, arg: arg})
+	}
+
+	return rules, nil
+}
+
+// checkRule checks the decoded value of field, through pointers, against r.
+func checkRule(field reflect.Value, raw string, r rule) error {
+	for field.Kind() == reflect.Ptr && !field.IsNil() {
+		field = field.Elem()
+	}
+
+	switch r.name {
+	case tagMin, tagMax:
+		return checkBound(field, r)
+	case tagOneOf:
+		for _, option := range strings.Split(r.arg, oneOfSeparator) {
+			if option == "" {
+				continue
+			}
+
+			expected := reflect.New(field.Type()).Elem()
+			if err := decode(expected, option); err != nil {
+				return fmt.Errorf("%w: %s: %w", errRuleInvalid, r, err)
+			}
+
+			if reflect.DeepEqual(expected.Interface(), field.Interface()) {
+				return nil
+			}
+		}
+
+		return errRuleOneOf
+	case tagRegex:
+		pattern, err := regexp.Compile(r.arg)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", errRuleInvalid, r, err)
+		}
+
+		if !pattern.MatchString(raw) {
+			return errRuleRegex
+		}
+	case tagURL:
+		u, err := url.Parse(raw)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return errRuleURL
+		}
+	case tagNonEmpty:
+		if isEmpty(field) {
+			return errRuleNonEmpty
+		}
+	}
+
+	return nil
+}
+
+func checkBound(field reflect.Value, r rule) error {
+	failure := errRuleMin
+	if r.name == tagMax {
+		failure = errRuleMax
+	}
+
+	var cmp int
+
+	switch field.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		bound, err := strconv.Atoi(r.arg)
+		if err != nil {
+			return fmt.Errorf("%w: %s", errRuleInvalid, r)
+		}
+
+		cmp = compare(float64(field.Len()), float64(bound))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		bound := reflect.New(field.Type()).Elem()
+		if err := decode(bound, r.arg); err != nil {
+			return fmt.Errorf("%w: %s", errRuleInvalid, r)
+		}
+
+		cmp = compare(toFloat(field), toFloat(bound))
+	default:
+		return fmt.Errorf("%w: %s: type \"%s\"", errRuleInvalid, r, field.Type())
+	}
+
+	if (r.name == tagMin && cmp < 0) || (r.name == tagMax && cmp > 0) {
+		return failure
+	}
+
+	return nil
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
+
+func compare(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	default:
+		return v.IsZero()
+	}
+}
+
+func runValidate(value any) error {
+	v, ok := value.(validator)
+	if !ok {
+		return nil
+	}
+
+	if err := v.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", errValidateFails, err)
+	}
+
+	return nil
+}
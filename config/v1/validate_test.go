@@ -0,0 +1,110 @@
+package v1
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRulesConfig struct {
+	RulePort     int           `min:"1" max:"65535"`
+	RuleTimeout  time.Duration `min:"1s" default:"0s"`
+	RuleLevel    string        `oneof:"debug info warn"`
+	RuleName     string        `regex:"^[a-z]+$" max:"5"`
+	RuleEndpoint string        `url:"true"`
+	RuleHosts    []string      `nonempty:"true" required:"false"`
+}
+
+type testHookConfig struct {
+	HookMin int
+	HookMax int
+}
+
+func (c *testHookConfig) Validate() error {
+	if c.HookMin > c.HookMax {
+		return errors.New("HOOK_MIN must be lower than HOOK_MAX")
+	}
+
+	return nil
+}
+
+func TestNew_Rules(t *testing.T) {
+	t.Setenv("RULE_PORT", "0")
+	t.Setenv("RULE_LEVEL", "trace")
+	t.Setenv("RULE_NAME", "Abcdefg")
+	t.Setenv("RULE_ENDPOINT", "localhost")
+	t.Setenv("RULE_HOSTS", " ")
+
+	_, err := New[testRulesConfig]()
+	assert.Equal(t, "config: 7 invalid variables: "+
+		"RULE_PORT (int, min=1): value is below minimum: value \"0\"; "+
+		"RULE_TIMEOUT (time.Duration, min=1s): value is below minimum: value \"0s\"; "+
+		"RULE_LEVEL (string, oneof=debug info warn): value is not one of the allowed values: value \"trace\"; "+
+		"RULE_NAME (string, max=5): value is above maximum: value \"Abcdefg\"; "+
+		"RULE_NAME (string, regex=^[a-z]+$): value does not match pattern: value \"Abcdefg\"; "+
+		"RULE_ENDPOINT (string, url): value is not an absolute url: value \"localhost\"; "+
+		"RULE_HOSTS ([]string, nonempty): value cannot be empty: value \" \"", err.Error())
+
+	var report Errors
+	assert.True(t, errors.As(err, &report))
+	assert.Len(t, report, 7)
+	assert.True(t, errors.Is(err, errRuleOneOf))
+
+	t.Setenv("RULE_PORT", "8080")
+	t.Setenv("RULE_TIMEOUT", "2s")
+	t.Setenv("RULE_LEVEL", "info")
+	t.Setenv("RULE_NAME", "abc")
+	t.Setenv("RULE_ENDPOINT", "https://localhost:8080")
+	t.Setenv("RULE_HOSTS", "a,b")
+
+	env, err := New[testRulesConfig]()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &testRulesConfig{
+		RulePort:     8080,
+		RuleTimeout:  2 * time.Second,
+		RuleLevel:    "info",
+		RuleName:     "abc",
+		RuleEndpoint: "https://localhost:8080",
+		RuleHosts:    []string{"a", "b"},
+	}, env.Get())
+}
+
+func TestNew_ValidateHook(t *testing.T) {
+	t.Setenv("HOOK_MIN", "10")
+	t.Setenv("HOOK_MAX", "1")
+
+	_, err := New[testHookConfig]()
+	assert.Equal(t, "config: validation failed: HOOK_MIN must be lower than HOOK_MAX", err.Error())
+
+	t.Setenv("HOOK_MAX", "20")
+
+	env, err := New[testHookConfig]()
+	assert.Equal(t, nil, err)
+	assert.Equal(t, &testHookConfig{HookMin: 10, HookMax: 20}, env.Get())
+}
+
+type testRulesDefaultConfig struct {
+	DefaultLevel   string  `required:"false" default:"trace" oneof:"debug info"`
+	DefaultWorkers *int    `required:"false" default:"0" min:"1"`
+	DefaultRatio   float64 `default:"1.5" max:"1"`
+	DefaultName    *string `required:"false" oneof:"a b"`
+}
+
+func TestLoad_RulesOnDefaults(t *testing.T) {
+	t.Parallel()
+
+	_, err := Load[testRulesDefaultConfig](NewLoader(nil, nil, nil))
+	assert.Equal(t, "config: 3 invalid variables: "+
+		"DEFAULT_LEVEL (string, oneof=debug info): value is not one of the allowed values: value \"trace\"; "+
+		"DEFAULT_WORKERS (*int, min=1): value is below minimum: value \"0\"; "+
+		"DEFAULT_RATIO (float64, max=1): value is above maximum: value \"1.5\"", err.Error())
+
+	env, err := Load[testRulesDefaultConfig](NewLoader([]string{
+		"-DEFAULT_LEVEL=info", "-DEFAULT_WORKERS=2", "-DEFAULT_RATIO=0.5", "-DEFAULT_NAME=b",
+	}, nil, nil))
+	assert.Equal(t, nil, err)
+	assert.Equal(t, 2, *env.Get().DefaultWorkers)
+	assert.Equal(t, "b", *env.Get().DefaultName)
+}
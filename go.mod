@@ -3,6 +3,7 @@ module github.com/ampliway/way-lib-go
 go 1.20
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/IBM/sarama v1.40.1
 	github.com/iancoleman/strcase v0.2.0
 	github.com/minio/minio-go v6.0.14+incompatible
@@ -12,6 +13,8 @@ require (
 	github.com/stretchr/testify v1.8.4
 	github.com/xdg-go/scram v1.1.2
 	golang.org/x/sync v0.3.0
+	golang.org/x/term v0.11.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -44,5 +47,4 @@ require (
 	golang.org/x/net v0.14.0 // indirect
 	golang.org/x/sys v0.11.0 // indirect
 	golang.org/x/text v0.12.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -12,4 +12,6 @@ var (
 	errAdminClientStart   = errors.New("start admin client failed")
 	errUnmarshal          = errors.New("unmarshal failed")
 	errPublish            = errors.New("publish message failed")
+	errSASLAlgorithm      = errors.New("invalid SASL algorithm, can be either \"sha256\" or \"sha512\"")
+	errTLSConfig          = errors.New("load TLS certificates failed")
 )
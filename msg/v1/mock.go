@@ -0,0 +1,75 @@
+package v1
+
+import (
+	"sync"
+
+	"github.com/ampliway/way-lib-go/msg"
+)
+
+var _ msg.ProducerV1 = (*Mock)(nil)
+
+type MockMessage struct {
+	Topic   string
+	Key     string
+	Message interface{}
+}
+
+type Mock struct {
+	mux      sync.Mutex
+	messages []MockMessage
+	topics   map[string]bool
+	shutdown bool
+}
+
+func NewMock() *Mock {
+	return &Mock{
+		mux:      sync.Mutex{},
+		messages: []MockMessage{},
+		topics:   map[string]bool{},
+	}
+}
+
+func (m *Mock) Publish(key string, message interface{}) error {
+	return m.PublishT(topicName(message), key, message)
+}
+
+func (m *Mock) PublishT(topicName, key string, message interface{}) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.topics[topicName] = true
+	m.messages = append(m.messages, MockMessage{Topic: topicName, Key: key, Message: message})
+
+	return nil
+}
+
+func (m *Mock) CreateTopicIfNotExist(topicName string, numPartitions int32, replicationFactor int16) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.topics[topicName] = true
+
+	return nil
+}
+
+func (m *Mock) Shutdown() {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	m.shutdown = true
+}
+
+// Messages lists what was published, in order.
+func (m *Mock) Messages() []MockMessage {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	return append([]MockMessage{}, m.messages...)
+}
+
+func (m *Mock) IsShutdown() bool {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	return m.shutdown
+}
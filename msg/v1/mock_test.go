@@ -0,0 +1,28 @@
+package v1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testOrderCreated struct {
+	ID string
+}
+
+func TestMock(t *testing.T) {
+	t.Parallel()
+
+	mock := NewMock()
+
+	assert.Equal(t, nil, mock.Publish("k1", testOrderCreated{ID: "1"}))
+	assert.Equal(t, nil, mock.PublishT("orders", "k2", testOrderCreated{ID: "2"}))
+	assert.Equal(t, []MockMessage{
+		{Topic: topicName(testOrderCreated{}), Key: "k1", Message: testOrderCreated{ID: "1"}},
+		{Topic: "orders", Key: "k2", Message: testOrderCreated{ID: "2"}},
+	}, mock.Messages())
+
+	assert.False(t, mock.IsShutdown())
+	mock.Shutdown()
+	assert.True(t, mock.IsShutdown())
+}
@@ -5,7 +5,6 @@ import (
 	"crypto/x509"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"strings"
 	"sync"
@@ -36,7 +35,10 @@ func New(cfg *Config, id id.ID) (*Producer, error) {
 		return nil, fmt.Errorf("%s: %w", msg.MODULE_NAME, errConfigServersEmpty)
 	}
 
-	config := defaultConfig(cfg)
+	config, err := defaultConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
 
 	servers := strings.Split(cfg.KafkaServers, ",")
 
@@ -147,7 +149,7 @@ func (p *Producer) CreateTopicIfNotExist(topicName string, numPartitions int32,
 	return nil
 }
 
-func defaultConfig(cfg *Config) *sarama.Config {
+func defaultConfig(cfg *Config) (*sarama.Config, error) {
 	clientID, _ := os.Hostname()
 
 	config := sarama.NewConfig()
@@ -170,37 +172,40 @@ func defaultConfig(cfg *Config) *sarama.Config {
 			config.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient { return &XDGSCRAMClient{HashGeneratorFcn: SHA256} }
 			config.Net.SASL.Mechanism = sarama.SASLTypeSCRAMSHA256
 		} else {
-			log.Fatalf("invalid SHA algorithm \"%s\": can be either \"sha256\" or \"sha512\"", cfg.KafkaAlgorithm)
+			return nil, fmt.Errorf("%s: %w: %q", msg.MODULE_NAME, errSASLAlgorithm, cfg.KafkaAlgorithm)
 		}
 	}
 
 	if cfg.KafkaCAFile != "" {
+		tlsConfig, err := createTLSConfiguration(cfg)
+		if err != nil {
+			return nil, err
+		}
+
 		config.Net.TLS.Enable = true
-		config.Net.TLS.Config = createTLSConfiguration(cfg)
+		config.Net.TLS.Config = tlsConfig
 	}
 
-	return config
+	return config, nil
 }
 
-func createTLSConfiguration(cfg *Config) (t *tls.Config) {
+func createTLSConfiguration(cfg *Config) (*tls.Config, error) {
 	cert, err := tls.LoadX509KeyPair(cfg.KafkaCertFile, cfg.KafkaKeyFile)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("%s: %w: %w", msg.MODULE_NAME, errTLSConfig, err)
 	}
 
 	caCert, err := os.ReadFile(cfg.KafkaCAFile)
 	if err != nil {
-		log.Fatal(err)
+		return nil, fmt.Errorf("%s: %w: %w", msg.MODULE_NAME, errTLSConfig, err)
 	}
 
 	caCertPool := x509.NewCertPool()
 	caCertPool.AppendCertsFromPEM(caCert)
 
-	t = &tls.Config{
+	return &tls.Config{
 		Certificates:       []tls.Certificate{cert},
 		RootCAs:            caCertPool,
 		InsecureSkipVerify: true,
-	}
-
-	return t
+	}, nil
 }
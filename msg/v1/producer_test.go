@@ -0,0 +1,47 @@
+package v1
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/ampliway/way-lib-go/helper/id"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew_Misconfigured(t *testing.T) {
+	t.Parallel()
+
+	missing := filepath.Join(t.TempDir(), "missing.pem")
+
+	tableTest := []struct {
+		Scenario    string
+		Config      *Config
+		ExpectedErr string
+	}{
+		{
+			Scenario:    "null",
+			ExpectedErr: "msg: config cannot be null",
+		},
+		{
+			Scenario:    "sasl_algorithm",
+			Config:      &Config{KafkaServers: "localhost:9092", KafkaUsername: "user", KafkaAlgorithm: "md5"},
+			ExpectedErr: `msg: invalid SASL algorithm, can be either "sha256" or "sha512": "md5"`,
+		},
+		{
+			Scenario:    "tls_files",
+			Config:      &Config{KafkaServers: "localhost:9092", KafkaCAFile: missing, KafkaCertFile: missing, KafkaKeyFile: missing},
+			ExpectedErr: "msg: load TLS certificates failed: open " + missing + ": no such file or directory",
+		},
+	}
+
+	for _, rowTest := range tableTest {
+		rowTest := rowTest
+
+		t.Run(rowTest.Scenario, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := New(rowTest.Config, id.New())
+			assert.Equal(t, rowTest.ExpectedErr, err.Error())
+		})
+	}
+}
@@ -46,9 +46,12 @@ func (s *Subscriber[T]) Subscribe(queueGroup string, execution func(m *msg.Messa
 }
 
 func (s *Subscriber[T]) SubscribeT(topicName, queueGroup string, execution func(msg *msg.Message[T]) bool) error {
-	config := defaultConfig(s.cfg)
+	config, err := defaultConfig(s.cfg)
+	if err != nil {
+		return err
+	}
 
-	err := s.producer.CreateTopicIfNotExist(topicName, 3, 3)
+	err = s.producer.CreateTopicIfNotExist(topicName, 3, 3)
 	if err != nil {
 		return err
 	}
@@ -7,4 +7,7 @@ import (
 var (
 	errConfigNull          = errors.New("config cannot be null")
 	errConfigFilePathEmpty = errors.New("filePath cannot be empty")
+	errStorageConnect      = errors.New("storage cannot connect")
+	errBucketCreate        = errors.New("create bucket failed")
+	errLifecycleSet        = errors.New("set bucket lifecycle failed")
 )
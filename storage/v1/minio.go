@@ -2,7 +2,7 @@ package v1
 
 import (
 	"encoding/xml"
-	"log"
+	"fmt"
 	"net/url"
 	"time"
 
@@ -22,6 +22,10 @@ type Minio struct {
 }
 
 func New(cfg *Config, id id.ID) (*Minio, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("%s: %w", storage.MODULE_NAME, errConfigNull)
+	}
+
 	client, err := minio.New(
 		cfg.StorageEndpoint,
 		cfg.StorageAccessKeyID,
@@ -29,19 +33,19 @@ func New(cfg *Config, id id.ID) (*Minio, error) {
 		cfg.StorageSecure,
 	)
 	if err != nil {
-		log.Fatalln(err)
+		return nil, fmt.Errorf("%s: %w: %w", storage.MODULE_NAME, errStorageConnect, err)
 	}
 
 	bucketName := reflection.AppNamePkg()
 	exist, err := client.BucketExists(bucketName)
 	if err != nil {
-		log.Fatalln(err)
+		return nil, fmt.Errorf("%s: %w: %w", storage.MODULE_NAME, errStorageConnect, err)
 	}
 
 	if !exist {
 		err := client.MakeBucket(bucketName, "")
 		if err != nil {
-			log.Fatalln(err)
+			return nil, fmt.Errorf("%s: %w: %w", storage.MODULE_NAME, errBucketCreate, err)
 		}
 	}
 
@@ -59,12 +63,12 @@ func New(cfg *Config, id id.ID) (*Minio, error) {
 
 		buf, err := xml.Marshal(config)
 		if err != nil {
-			log.Fatalln(err)
+			return nil, fmt.Errorf("%s: %w: %w", storage.MODULE_NAME, errLifecycleSet, err)
 		}
 
 		err = client.SetBucketLifecycle(bucketName, string(buf))
 		if err != nil {
-			log.Fatalln(err)
+			return nil, fmt.Errorf("%s: %w: %w", storage.MODULE_NAME, errLifecycleSet, err)
 		}
 	}
 
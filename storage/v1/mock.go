@@ -0,0 +1,73 @@
+package v1
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ampliway/way-lib-go/helper/id"
+	"github.com/ampliway/way-lib-go/storage"
+)
+
+var _ storage.V1 = (*Mock)(nil)
+
+type Mock struct {
+	mux     sync.Mutex
+	id      id.ID
+	objects map[string]storage.SaveConfig
+}
+
+func NewMock() *Mock {
+	return &Mock{
+		mux:     sync.Mutex{},
+		id:      id.New(),
+		objects: map[string]storage.SaveConfig{},
+	}
+}
+
+func (m *Mock) Save(config *storage.SaveConfig) (string, error) {
+	if config == nil {
+		return "", errConfigNull
+	}
+
+	if config.FilePath == "" {
+		return "", errConfigFilePathEmpty
+	}
+
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	if config.Name == "" {
+		config.Name = m.id.Random()
+	}
+
+	m.objects[config.Name] = *config
+
+	return config.Name, nil
+}
+
+func (m *Mock) Delete(objectName string) error {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	delete(m.objects, objectName)
+
+	return nil
+}
+
+func (m *Mock) Link(objectName string, expiration time.Duration) (string, error) {
+	return fmt.Sprintf("mock://%s?expires=%s", objectName, expiration), nil
+}
+
+// Objects lists what is stored, by object name.
+func (m *Mock) Objects() map[string]storage.SaveConfig {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+
+	result := make(map[string]storage.SaveConfig, len(m.objects))
+	for name, object := range m.objects {
+		result[name] = object
+	}
+
+	return result
+}
@@ -0,0 +1,36 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ampliway/way-lib-go/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMock(t *testing.T) {
+	t.Parallel()
+
+	mock := NewMock()
+
+	_, err := mock.Save(nil)
+	assert.Equal(t, errConfigNull, err)
+
+	_, err = mock.Save(&storage.SaveConfig{})
+	assert.Equal(t, errConfigFilePathEmpty, err)
+
+	name, err := mock.Save(&storage.SaveConfig{FilePath: "/tmp/report.csv"})
+	assert.Equal(t, nil, err)
+	assert.Len(t, name, 26)
+
+	_, err = mock.Save(&storage.SaveConfig{Name: "fixed", FilePath: "/tmp/a.csv"})
+	assert.Equal(t, nil, err)
+	assert.Len(t, mock.Objects(), 2)
+
+	link, err := mock.Link("fixed", time.Minute)
+	assert.Equal(t, nil, err)
+	assert.Equal(t, "mock://fixed?expires=1m0s", link)
+
+	assert.Equal(t, nil, mock.Delete("fixed"))
+	assert.Len(t, mock.Objects(), 1)
+}